@@ -0,0 +1,758 @@
+package github
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// DeployKey is an SSH key with access to a single repo.
+type DeployKey struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Key       string    `json:"key"`
+	ReadOnly  bool      `json:"read_only"`
+	Verified  bool      `json:"verified"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListDeployKeys lists the deploy keys of the repo.
+// NOTE: listing the deploy keys requires admin access to the repo.
+func (c *Client) ListDeployKeys(owner string, repo string) ([]*DeployKey, error) {
+	var all []*DeployKey
+	var page []*DeployKey
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("repos/%v/%v/keys", owner, repo)
+			u, err := addOptions(u, opt)
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// AppInstallation is a GitHub App installed on an org.
+type AppInstallation struct {
+	ID      int64  `json:"id"`
+	AppID   int64  `json:"app_id"`
+	AppSlug string `json:"app_slug"`
+	// RepositorySelection is "all" or "selected".
+	RepositorySelection string            `json:"repository_selection"`
+	Permissions         map[string]string `json:"permissions"`
+	Events              []string          `json:"events"`
+	CreatedAt           time.Time         `json:"created_at"`
+}
+
+type appInstallationsPage struct {
+	TotalCount    int                `json:"total_count"`
+	Installations []*AppInstallation `json:"installations"`
+}
+
+// ListInstallations lists the GitHub Apps installed on the org.
+// NOTE: only the owners of the org can list the installations.
+func (c *Client) ListInstallations(org string) ([]*AppInstallation, error) {
+	var all []*AppInstallation
+	var page *appInstallationsPage
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("orgs/%v/installations", org)
+			u, err := addOptions(u, opt)
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = new(appInstallationsPage)
+			return c.client.Do(ctx, req, page)
+		},
+		func() {
+			all = append(all, page.Installations...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// AccessReviewMember is a member of the org.
+type AccessReviewMember struct {
+	Login string `json:"login"`
+	// Role is MemberRoleAdmin or MemberRoleMember.
+	Role string `json:"role"`
+	// TwoFactorDisabled is nil when the 2FA status could not be listed.
+	TwoFactorDisabled *bool `json:"two_factor_disabled,omitempty"`
+}
+
+// AccessReviewTeamMember is a member of a team.
+type AccessReviewTeamMember struct {
+	Login string `json:"login"`
+	Role  string `json:"role"`
+}
+
+// AccessReviewTeamRepo is a repo a team has access to.
+type AccessReviewTeamRepo struct {
+	Repo       string     `json:"repo"`
+	Permission Permission `json:"permission"`
+}
+
+// AccessReviewTeam is a team of the org.
+type AccessReviewTeam struct {
+	Slug    string                    `json:"slug"`
+	Name    string                    `json:"name"`
+	Parent  string                    `json:"parent,omitempty"`
+	Privacy string                    `json:"privacy"`
+	Members []*AccessReviewTeamMember `json:"members"`
+	Repos   []*AccessReviewTeamRepo   `json:"repos"`
+}
+
+// AccessReviewDeployKey is a deploy key of a repo.
+type AccessReviewDeployKey struct {
+	Repo string `json:"repo"`
+	DeployKey
+}
+
+// AccessReview is everyone and everything with access to an org.
+type AccessReview struct {
+	Org         string    `json:"org"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Members              []*AccessReviewMember    `json:"members"`
+	OutsideCollaborators []string                 `json:"outside_collaborators"`
+	Teams                []*AccessReviewTeam      `json:"teams"`
+	Permissions          *PermissionMatrix        `json:"permissions"`
+	DeployKeys           []*AccessReviewDeployKey `json:"deploy_keys"`
+	Apps                 []*AppInstallation       `json:"apps"`
+
+	// Warnings are the parts of the review that could not be built
+	// (e.g. because the authenticated user is not an owner of the org).
+	Warnings []*AccessReviewWarning `json:"warnings,omitempty"`
+}
+
+// AccessReviewWarning is a part of a review that could not be built.
+type AccessReviewWarning struct {
+	// Kind is the kind of the entries that are missing (see AccessEntry.Kind).
+	Kind string `json:"kind"`
+	// Target, if set, restricts the missing entries to the ones of the target (e.g. a repo).
+	Target  string `json:"target,omitempty"`
+	Message string `json:"message"`
+}
+
+// covers tells whether the entry is one of the missing ones.
+func (w *AccessReviewWarning) covers(entry *AccessEntry) bool {
+	return entry.Kind == w.Kind && (w.Target == "" || entry.Target == w.Target)
+}
+
+type AccessReviewOpts struct {
+	// IncludeForks and IncludeArchived include the forks and the archived repos
+	// in the permissions and the deploy keys.
+	IncludeForks    bool
+	IncludeArchived bool
+	// Concurrency is the number of repos (and teams) processed at the same time;
+	// values <= 1 mean one at a time.
+	Concurrency int
+}
+
+// BuildAccessReview builds the access review of the org:
+// members and roles, outside collaborators, teams, per-repo permissions,
+// deploy keys and installed apps.
+// A nil opts includes all the repos.
+// NOTE: a complete review requires the authenticated user to be an owner of the org.
+func (c *Client) BuildAccessReview(org string, opts *AccessReviewOpts) (*AccessReview, error) {
+	if opts == nil {
+		opts = &AccessReviewOpts{
+			IncludeForks:    true,
+			IncludeArchived: true,
+		}
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	review := &AccessReview{
+		Org:         org,
+		GeneratedAt: time.Now(),
+	}
+
+	// members:
+	admins, err := c.ListOrgMembers(org, &ListOrgMembersOpts{Role: MemberRoleAdmin})
+	if err != nil {
+		return nil, fmt.Errorf("error while listing admins: %w", err)
+	}
+	members, err := c.ListOrgMembers(org, &ListOrgMembersOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("error while listing members: %w", err)
+	}
+	isAdmin := make(map[string]bool)
+	for _, user := range admins {
+		isAdmin[user.GetLogin()] = true
+	}
+	no2FA := make(map[string]bool)
+	disabled, err := c.ListOrgMembers(org, &ListOrgMembersOpts{Filter: MemberFilter2FADisabled})
+	known2FA := err == nil
+	if err != nil {
+		review.Warnings = append(review.Warnings, &AccessReviewWarning{
+			Kind:    "two_factor_disabled",
+			Message: Sf("2FA status of members: %s", err),
+		})
+	}
+	for _, user := range disabled {
+		no2FA[user.GetLogin()] = true
+	}
+	for _, user := range members {
+		member := &AccessReviewMember{
+			Login: user.GetLogin(),
+			Role:  MemberRoleMember,
+		}
+		if known2FA {
+			member.TwoFactorDisabled = github.Bool(no2FA[user.GetLogin()])
+		}
+		if isAdmin[user.GetLogin()] {
+			member.Role = MemberRoleAdmin
+		}
+		review.Members = append(review.Members, member)
+	}
+
+	// outside collaborators:
+	collaborators, err := c.ListOutsideCollaborators(org, OutsideCollaboratorsFilterAll)
+	if err != nil {
+		review.Warnings = append(review.Warnings, &AccessReviewWarning{
+			Kind:    "outside_collaborator",
+			Message: Sf("outside collaborators: %s", err),
+		})
+	}
+	for _, user := range collaborators {
+		review.OutsideCollaborators = append(review.OutsideCollaborators, user.GetLogin())
+	}
+
+	// teams:
+	teams, err := c.ListTeams(org)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListTeams: %w", err)
+	}
+	review.Teams = make([]*AccessReviewTeam, len(teams))
+	group := NewSizedGroup(int64(concurrency))
+	for i := range teams {
+		index := i
+		group.Go(func() error {
+			team := teams[index]
+			teamMembers, err := c.ListTeamMembersWithRoles(org, team.GetSlug())
+			if err != nil {
+				return fmt.Errorf("error while listing members of team %s: %w", team.GetSlug(), err)
+			}
+			teamRepos, err := c.ListTeamRepos(org, team.GetSlug())
+			if err != nil {
+				return fmt.Errorf("error while listing repos of team %s: %w", team.GetSlug(), err)
+			}
+			entry := &AccessReviewTeam{
+				Slug:    team.GetSlug(),
+				Name:    team.GetName(),
+				Parent:  team.GetParent().GetSlug(),
+				Privacy: team.GetPrivacy(),
+			}
+			for _, member := range teamMembers {
+				entry.Members = append(entry.Members, &AccessReviewTeamMember{
+					Login: member.User.GetLogin(),
+					Role:  member.Role,
+				})
+			}
+			for _, repo := range teamRepos {
+				entry.Repos = append(entry.Repos, &AccessReviewTeamRepo{
+					Repo:       repo.GetName(),
+					Permission: repo.Permission(),
+				})
+			}
+			review.Teams[index] = entry
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// permissions:
+	review.Permissions, err = c.BuildPermissionMatrix(org, &PermissionMatrixOpts{
+		IncludeForks:    opts.IncludeForks,
+		IncludeArchived: opts.IncludeArchived,
+		Concurrency:     concurrency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while BuildPermissionMatrix: %w", err)
+	}
+
+	// deploy keys (admin access to each repo is required):
+	var mu sync.Mutex
+	var keyWarnings []*AccessReviewWarning
+	group = NewSizedGroup(int64(concurrency))
+	for i := range review.Permissions.Repos {
+		repo := review.Permissions.Repos[i]
+		group.Go(func() error {
+			keys, err := c.ListDeployKeys(org, repo)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				keyWarnings = append(keyWarnings, &AccessReviewWarning{
+					Kind:    "deploy_key",
+					Target:  repo,
+					Message: Sf("deploy keys of %s: %s", repo, err),
+				})
+				return nil
+			}
+			for _, key := range keys {
+				review.DeployKeys = append(review.DeployKeys, &AccessReviewDeployKey{
+					Repo:      repo,
+					DeployKey: *key,
+				})
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keyWarnings, func(i, j int) bool {
+		return keyWarnings[i].Target < keyWarnings[j].Target
+	})
+	review.Warnings = append(review.Warnings, keyWarnings...)
+
+	// apps:
+	review.Apps, err = c.ListInstallations(org)
+	if err != nil {
+		review.Warnings = append(review.Warnings, &AccessReviewWarning{
+			Kind:    "app",
+			Message: Sf("installed apps: %s", err),
+		})
+	}
+
+	review.sort()
+	return review, nil
+}
+
+func (r *AccessReview) sort() {
+	sort.Slice(r.Members, func(i, j int) bool {
+		return r.Members[i].Login < r.Members[j].Login
+	})
+	sort.Strings(r.OutsideCollaborators)
+	sort.Slice(r.Teams, func(i, j int) bool {
+		return r.Teams[i].Slug < r.Teams[j].Slug
+	})
+	for _, team := range r.Teams {
+		sort.Slice(team.Members, func(i, j int) bool {
+			return team.Members[i].Login < team.Members[j].Login
+		})
+		sort.Slice(team.Repos, func(i, j int) bool {
+			return team.Repos[i].Repo < team.Repos[j].Repo
+		})
+	}
+	sort.Slice(r.DeployKeys, func(i, j int) bool {
+		if r.DeployKeys[i].Repo != r.DeployKeys[j].Repo {
+			return r.DeployKeys[i].Repo < r.DeployKeys[j].Repo
+		}
+		return r.DeployKeys[i].ID < r.DeployKeys[j].ID
+	})
+	sort.Slice(r.Apps, func(i, j int) bool {
+		return r.Apps[i].AppSlug < r.Apps[j].AppSlug
+	})
+}
+
+// AccessEntry is a single grant of access in a review.
+type AccessEntry struct {
+	// Kind is one of "member", "two_factor_disabled", "outside_collaborator",
+	// "team_member", "team_repo", "repo_permission", "deploy_key", "app".
+	Kind string `json:"kind"`
+	// Subject is who has the access (user, team, key, app).
+	Subject string `json:"subject"`
+	// Target is what the access is to (org, team, repo).
+	Target string `json:"target"`
+	// Access is the level of the access (role, permission).
+	Access string `json:"access"`
+	// Details are the other attributes of the grant.
+	Details string `json:"details,omitempty"`
+}
+
+func (e *AccessEntry) key() string {
+	return e.Kind + "\x00" + e.Subject + "\x00" + e.Target
+}
+
+// Entries flattens the review into a list of grants.
+func (r *AccessReview) Entries() []*AccessEntry {
+	var entries []*AccessEntry
+	for _, member := range r.Members {
+		entries = append(entries, &AccessEntry{
+			Kind:    "member",
+			Subject: member.Login,
+			Target:  r.Org,
+			Access:  member.Role,
+		})
+	}
+	for _, member := range r.Members {
+		if member.TwoFactorDisabled != nil && *member.TwoFactorDisabled {
+			entries = append(entries, &AccessEntry{
+				Kind:    "two_factor_disabled",
+				Subject: member.Login,
+				Target:  r.Org,
+			})
+		}
+	}
+	for _, login := range r.OutsideCollaborators {
+		entries = append(entries, &AccessEntry{
+			Kind:    "outside_collaborator",
+			Subject: login,
+			Target:  r.Org,
+		})
+	}
+	for _, team := range r.Teams {
+		for _, member := range team.Members {
+			entries = append(entries, &AccessEntry{
+				Kind:    "team_member",
+				Subject: member.Login,
+				Target:  team.Slug,
+				Access:  member.Role,
+			})
+		}
+		for _, repo := range team.Repos {
+			entries = append(entries, &AccessEntry{
+				Kind:    "team_repo",
+				Subject: team.Slug,
+				Target:  repo.Repo,
+				Access:  string(repo.Permission),
+			})
+		}
+	}
+	if r.Permissions != nil {
+		for _, user := range r.Permissions.Users {
+			var repos []string
+			for repo := range r.Permissions.Access[user] {
+				repos = append(repos, repo)
+			}
+			sort.Strings(repos)
+			for _, repo := range repos {
+				access := r.Permissions.Access[user][repo]
+				details := []string{"direct=" + string(access.Direct)}
+				for _, grant := range access.Teams {
+					details = append(details, Sf("team:%s=%s", grant.Team, grant.Permission))
+				}
+				entries = append(entries, &AccessEntry{
+					Kind:    "repo_permission",
+					Subject: user,
+					Target:  repo,
+					Access:  string(access.Permission),
+					Details: strings.Join(details, " "),
+				})
+			}
+		}
+	}
+	for _, key := range r.DeployKeys {
+		access := "read-write"
+		if key.ReadOnly {
+			access = "read-only"
+		}
+		entries = append(entries, &AccessEntry{
+			Kind:    "deploy_key",
+			Subject: Sf("%s (%d)", key.Title, key.ID),
+			Target:  key.Repo,
+			Access:  access,
+			Details: "created_at=" + formatCSVTime(key.CreatedAt),
+		})
+	}
+	for _, app := range r.Apps {
+		var permissions []string
+		for name, level := range app.Permissions {
+			permissions = append(permissions, name+"="+level)
+		}
+		sort.Strings(permissions)
+		entries = append(entries, &AccessEntry{
+			Kind:    "app",
+			Subject: app.AppSlug,
+			Target:  r.Org,
+			Access:  app.RepositorySelection,
+			Details: strings.Join(permissions, " "),
+		})
+	}
+	return entries
+}
+
+// WriteJSON writes the review as indented JSON;
+// it can be loaded back with LoadAccessReview.
+func (r *AccessReview) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// LoadAccessReview loads a review written with WriteJSON.
+func LoadAccessReview(path string) (*AccessReview, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var review AccessReview
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("error while decoding access review %s: %w", path, err)
+	}
+	return &review, nil
+}
+
+// WriteCSV writes the review as CSV, one row per grant (see Entries).
+func (r *AccessReview) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"kind", "subject", "target", "access", "details"}); err != nil {
+		return err
+	}
+	for _, entry := range r.Entries() {
+		err := writer.Write([]string{entry.Kind, entry.Subject, entry.Target, entry.Access, entry.Details})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeMarkdownCell escapes the characters that would break a Markdown table.
+func escapeMarkdownCell(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
+
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) error {
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = escapeMarkdownCell(cell)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdown writes the review as a Markdown document.
+func (r *AccessReview) WriteMarkdown(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# Access review of %s\n\nGenerated at %s.\n\n", r.Org, formatCSVTime(r.GeneratedAt))
+	if err != nil {
+		return err
+	}
+	if len(r.Warnings) > 0 {
+		var b strings.Builder
+		b.WriteString("## Warnings\n\n")
+		for _, warning := range r.Warnings {
+			b.WriteString("- " + warning.Message + "\n")
+		}
+		b.WriteString("\n")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	sections := []struct {
+		kind   string
+		title  string
+		header []string
+	}{
+		{"member", "Members", []string{"Login", "Org", "Role", ""}},
+		{"two_factor_disabled", "Members with 2FA disabled", []string{"Login", "Org", "", ""}},
+		{"outside_collaborator", "Outside collaborators", []string{"Login", "Org", "Access", "Details"}},
+		{"team_member", "Team members", []string{"Login", "Team", "Role", ""}},
+		{"team_repo", "Team repositories", []string{"Team", "Repo", "Permission", ""}},
+		{"repo_permission", "Repository permissions", []string{"User", "Repo", "Permission", "Grants"}},
+		{"deploy_key", "Deploy keys", []string{"Key", "Repo", "Access", "Details"}},
+		{"app", "Installed apps", []string{"App", "Org", "Repositories", "Permissions"}},
+	}
+	entries := r.Entries()
+	for _, section := range sections {
+		var rows [][]string
+		for _, entry := range entries {
+			if entry.Kind == section.kind {
+				rows = append(rows, []string{entry.Subject, entry.Target, entry.Access, entry.Details})
+			}
+		}
+		if _, err := fmt.Fprintf(w, "## %s (%d)\n\n", section.title, len(rows)); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := writeMarkdownTable(w, section.header, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AccessChange is a grant that changed between two reviews.
+type AccessChange struct {
+	Old *AccessEntry `json:"old"`
+	New *AccessEntry `json:"new"`
+}
+
+// AccessReviewDiff is the difference between two reviews.
+type AccessReviewDiff struct {
+	Org     string          `json:"org"`
+	OldAt   time.Time       `json:"old_at"`
+	NewAt   time.Time       `json:"new_at"`
+	Added   []*AccessEntry  `json:"added"`
+	Removed []*AccessEntry  `json:"removed"`
+	Changed []*AccessChange `json:"changed"`
+}
+
+// IsEmpty tells whether nothing changed.
+func (d *AccessReviewDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffAccessReviews compares two reviews of the same org.
+// The parts that could not be built in either review (see Warnings)
+// are left out: their missing entries are not changes.
+func DiffAccessReviews(old *AccessReview, new *AccessReview) (*AccessReviewDiff, error) {
+	if old == nil || new == nil {
+		return nil, errors.New("review is nil.")
+	}
+	if !strings.EqualFold(old.Org, new.Org) {
+		return nil, fmt.Errorf("reviews are of different orgs: %q and %q", old.Org, new.Org)
+	}
+	diff := &AccessReviewDiff{
+		Org:   new.Org,
+		OldAt: old.GeneratedAt,
+		NewAt: new.GeneratedAt,
+	}
+
+	warnings := append(append([]*AccessReviewWarning(nil), old.Warnings...), new.Warnings...)
+	entriesOf := func(review *AccessReview) []*AccessEntry {
+		var entries []*AccessEntry
+	Entries:
+		for _, entry := range review.Entries() {
+			for _, warning := range warnings {
+				if warning.covers(entry) {
+					continue Entries
+				}
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	oldEntries := make(map[string]*AccessEntry)
+	for _, entry := range entriesOf(old) {
+		oldEntries[entry.key()] = entry
+	}
+	newKeys := make(map[string]bool)
+	for _, entry := range entriesOf(new) {
+		newKeys[entry.key()] = true
+		previous, ok := oldEntries[entry.key()]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entry)
+		case previous.Access != entry.Access || previous.Details != entry.Details:
+			diff.Changed = append(diff.Changed, &AccessChange{Old: previous, New: entry})
+		}
+	}
+	for _, entry := range entriesOf(old) {
+		if !newKeys[entry.key()] {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+	return diff, nil
+}
+
+// DiffAgainstFile compares the review with a previous one written with WriteJSON.
+func (r *AccessReview) DiffAgainstFile(path string) (*AccessReviewDiff, error) {
+	previous, err := LoadAccessReview(path)
+	if err != nil {
+		return nil, err
+	}
+	return DiffAccessReviews(previous, r)
+}
+
+// WriteMarkdown writes the diff as a Markdown document.
+func (d *AccessReviewDiff) WriteMarkdown(w io.Writer) error {
+	_, err := fmt.Fprintf(
+		w,
+		"# Access changes of %s\n\nFrom %s to %s.\n\n",
+		d.Org,
+		formatCSVTime(d.OldAt),
+		formatCSVTime(d.NewAt),
+	)
+	if err != nil {
+		return err
+	}
+	header := []string{"Kind", "Subject", "Target", "Access", "Details"}
+	for _, section := range []struct {
+		title   string
+		entries []*AccessEntry
+	}{
+		{"Added", d.Added},
+		{"Removed", d.Removed},
+	} {
+		if _, err := fmt.Fprintf(w, "## %s (%d)\n\n", section.title, len(section.entries)); err != nil {
+			return err
+		}
+		if len(section.entries) == 0 {
+			continue
+		}
+		var rows [][]string
+		for _, entry := range section.entries {
+			rows = append(rows, []string{entry.Kind, entry.Subject, entry.Target, entry.Access, entry.Details})
+		}
+		if err := writeMarkdownTable(w, header, rows); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "## Changed (%d)\n\n", len(d.Changed)); err != nil {
+		return err
+	}
+	if len(d.Changed) == 0 {
+		return nil
+	}
+	arrow := func(old string, new string) string {
+		if old == new {
+			return new
+		}
+		return old + " → " + new
+	}
+	var rows [][]string
+	for _, change := range d.Changed {
+		rows = append(rows, []string{
+			change.New.Kind,
+			change.New.Subject,
+			change.New.Target,
+			arrow(change.Old.Access, change.New.Access),
+			arrow(change.Old.Details, change.New.Details),
+		})
+	}
+	return writeMarkdownTable(w, header, rows)
+}
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestDiffAccessReviewsWithWarnings(t *testing.T) {
+	old := &AccessReview{
+		Org: "org",
+		Members: []*AccessReviewMember{
+			{Login: "alice", Role: MemberRoleAdmin, TwoFactorDisabled: github.Bool(true)},
+			{Login: "bob", Role: MemberRoleMember, TwoFactorDisabled: github.Bool(false)},
+		},
+		DeployKeys: []*AccessReviewDeployKey{
+			{Repo: "a", DeployKey: DeployKey{ID: 1, Title: "ci"}},
+			{Repo: "b", DeployKey: DeployKey{ID: 2, Title: "ci"}},
+		},
+	}
+	new := &AccessReview{
+		Org: "org",
+		Members: []*AccessReviewMember{
+			{Login: "alice", Role: MemberRoleAdmin},
+			{Login: "carol", Role: MemberRoleMember},
+		},
+		Warnings: []*AccessReviewWarning{
+			{Kind: "two_factor_disabled", Message: "2FA status of members: forbidden"},
+			{Kind: "deploy_key", Target: "a", Message: "deploy keys of a: forbidden"},
+		},
+	}
+
+	diff, err := DiffAccessReviews(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changed) != 0 {
+		t.Errorf("got %d changes, expected none", len(diff.Changed))
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Kind != "member" || diff.Added[0].Subject != "carol" {
+		t.Errorf("added = %+v, expected member carol", diff.Added)
+	}
+	// the 2FA status and the deploy keys of a are unknown, not removed.
+	var removed []string
+	for _, entry := range diff.Removed {
+		removed = append(removed, entry.Kind+":"+entry.Subject+"@"+entry.Target)
+	}
+	if len(removed) != 2 || removed[0] != "member:bob@org" || removed[1] != "deploy_key:ci (2)@b" {
+		t.Errorf("removed = %v, expected member bob and the deploy key of b", removed)
+	}
+}
@@ -0,0 +1,522 @@
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ArchiveFormat string
+
+const (
+	// ArchiveTarball is a gzipped tar archive.
+	ArchiveTarball ArchiveFormat = "tarball"
+	// ArchiveZipball is a zip archive.
+	ArchiveZipball ArchiveFormat = "zipball"
+)
+
+var (
+	ErrUnsafeArchivePath = errors.New("unsafe path in archive")
+	ErrArchiveTooLarge   = errors.New("archive exceeds the size limits")
+)
+
+// ArchiveLimits are the caps enforced while downloading and extracting an archive.
+// A zero value means no limit.
+type ArchiveLimits struct {
+	// MaxArchiveSize is the max size of the downloaded (compressed) archive.
+	MaxArchiveSize int64
+	// MaxFileSize is the max size of a single extracted file.
+	MaxFileSize int64
+	// MaxTotalSize is the max size of all the extracted files together.
+	MaxTotalSize int64
+	// MaxFiles is the max number of extracted files.
+	MaxFiles int
+}
+
+// DefaultArchiveLimits are used when no limits are provided.
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxArchiveSize: 1 << 30,   // 1 GiB
+	MaxFileSize:    100 << 20, // 100 MiB
+	MaxTotalSize:   2 << 30,   // 2 GiB
+	MaxFiles:       500000,
+}
+
+// RepoArchive is the archive of a repository, as downloaded by DownloadArchive.
+// It must be extracted (or closed) by the caller.
+type RepoArchive struct {
+	format ArchiveFormat
+	body   io.ReadCloser
+}
+
+// DownloadArchive starts the download of the archive of the repo
+// at the ref set with WithRef (default branch if not set).
+// The archive is streamed: nothing is read until it is extracted.
+func (r *RepoExplorationRequest) DownloadArchive(format ArchiveFormat) (*RepoArchive, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if format != ArchiveTarball && format != ArchiveZipball {
+		return nil, fmt.Errorf("unknown archive format: %q", format)
+	}
+
+	client := r.client.client
+
+	u := fmt.Sprintf("repos/%s/%s/%s", r.params.owner, r.params.repo, format)
+	if r.params.ref != "" {
+		u += "/" + r.params.ref
+	}
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	started := make(chan error, 1)
+	go func() {
+		// NOTE: the archive endpoint redirects to the actual download URL,
+		// which is followed by the http client.
+		resp, err := client.Do(context.Background(), req, &startedWriter{w: pw, started: started})
+		if err == nil {
+			onResponse(resp)
+		}
+		started <- err
+		pw.CloseWithError(err)
+	}()
+
+	// wait for the first bytes (or for an error) before returning:
+	err = <-started
+	if err != nil {
+		pr.Close()
+		return nil, fmt.Errorf("error while downloading archive: %w", err)
+	}
+
+	return &RepoArchive{
+		format: format,
+		body:   pr,
+	}, nil
+}
+
+// startedWriter signals when the first write happens.
+type startedWriter struct {
+	w       io.Writer
+	started chan error
+	once    bool
+}
+
+func (sw *startedWriter) Write(p []byte) (int, error) {
+	if !sw.once {
+		sw.once = true
+		sw.started <- nil
+	}
+	return sw.w.Write(p)
+}
+
+// Close aborts the download.
+func (a *RepoArchive) Close() error {
+	return a.body.Close()
+}
+
+// ExtractTo extracts the archive into the dir, stripping
+// the top-level `owner-repo-sha/` prefix of the archive entries.
+// Symlinks and other special files are skipped.
+// If limits is nil, DefaultArchiveLimits are used.
+func (a *RepoArchive) ExtractTo(dir string, limits *ArchiveLimits) error {
+	defer a.Close()
+
+	sink := &dirSink{dir: dir}
+	if a.format == ArchiveTarball {
+		return extractTarball(a.body, sink, limitsOrDefault(limits))
+	}
+
+	// zip archives can't be streamed; spool to a temp file:
+	tmp, err := ioutil.TempFile("", "gh-client-zipball-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := copyLimited(tmp, a.body, limitsOrDefault(limits).MaxArchiveSize)
+	if err != nil {
+		return err
+	}
+	return extractZipball(tmp, size, sink, limitsOrDefault(limits))
+}
+
+// ExtractToFS extracts the archive into an in-memory fs.FS, stripping
+// the top-level `owner-repo-sha/` prefix of the archive entries.
+// Symlinks and other special files are skipped.
+// If limits is nil, DefaultArchiveLimits are used.
+func (a *RepoArchive) ExtractToFS(limits *ArchiveLimits) (fs.FS, error) {
+	defer a.Close()
+
+	sink := &memSink{fs: make(memFS)}
+	if a.format == ArchiveTarball {
+		err := extractTarball(a.body, sink, limitsOrDefault(limits))
+		if err != nil {
+			return nil, err
+		}
+		return sink.fs, nil
+	}
+
+	buf := new(bytes.Buffer)
+	size, err := copyLimited(buf, a.body, limitsOrDefault(limits).MaxArchiveSize)
+	if err != nil {
+		return nil, err
+	}
+	err = extractZipball(bytes.NewReader(buf.Bytes()), size, sink, limitsOrDefault(limits))
+	if err != nil {
+		return nil, err
+	}
+	return sink.fs, nil
+}
+
+func limitsOrDefault(limits *ArchiveLimits) ArchiveLimits {
+	if limits == nil {
+		return DefaultArchiveLimits
+	}
+	return *limits
+}
+
+// copyLimited copies from src to dst, returning ErrArchiveTooLarge
+// if there are more than max bytes (zero means no limit).
+func copyLimited(dst io.Writer, src io.Reader, max int64) (int64, error) {
+	if max <= 0 {
+		return io.Copy(dst, src)
+	}
+	n, err := io.Copy(dst, io.LimitReader(src, max+1))
+	if err != nil {
+		return n, err
+	}
+	if n > max {
+		return n, ErrArchiveTooLarge
+	}
+	return n, nil
+}
+
+// archiveSink is where the archive entries are extracted to.
+type archiveSink interface {
+	mkdir(name string) error
+	writeFile(name string, mode fs.FileMode, modTime time.Time, r io.Reader, max int64) (int64, error)
+}
+
+// archiveExtractor enforces the limits and the path safety
+// for all the archive formats.
+type archiveExtractor struct {
+	sink   archiveSink
+	limits ArchiveLimits
+
+	files     int
+	totalSize int64
+}
+
+func (ex *archiveExtractor) extract(rawName string, isDir bool, mode fs.FileMode, modTime time.Time, r io.Reader) error {
+	name, ok, err := stripArchivePrefix(rawName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// the top-level dir itself.
+		return nil
+	}
+	if isDir {
+		return ex.sink.mkdir(name)
+	}
+
+	ex.files++
+	if ex.limits.MaxFiles > 0 && ex.files > ex.limits.MaxFiles {
+		return fmt.Errorf("%w: more than %v files", ErrArchiveTooLarge, ex.limits.MaxFiles)
+	}
+
+	max := ex.limits.MaxFileSize
+	if ex.limits.MaxTotalSize > 0 {
+		left := ex.limits.MaxTotalSize - ex.totalSize
+		if left <= 0 {
+			return fmt.Errorf("%w: more than %v bytes", ErrArchiveTooLarge, ex.limits.MaxTotalSize)
+		}
+		if max <= 0 || left < max {
+			max = left
+		}
+	}
+	n, err := ex.sink.writeFile(name, mode, modTime, r, max)
+	ex.totalSize += n
+	if err != nil {
+		return fmt.Errorf("error while extracting %q: %w", name, err)
+	}
+	return nil
+}
+
+// stripArchivePrefix removes the top-level `owner-repo-sha/` dir
+// from the name of an archive entry, and validates the result.
+func stripArchivePrefix(rawName string) (string, bool, error) {
+	name := strings.TrimSuffix(rawName, "/")
+	slash := strings.Index(name, "/")
+	if slash < 0 {
+		if name == ".." || strings.Contains(name, `\`) {
+			return "", false, fmt.Errorf("%w: %q", ErrUnsafeArchivePath, rawName)
+		}
+		return "", false, nil
+	}
+	// the prefix must be a plain dir name: not empty (absolute entries), "." or "..".
+	if prefix := name[:slash]; !fs.ValidPath(prefix) || prefix == "." || strings.Contains(prefix, `\`) {
+		return "", false, fmt.Errorf("%w: %q", ErrUnsafeArchivePath, rawName)
+	}
+	name = name[slash+1:]
+	if !fs.ValidPath(name) || strings.Contains(name, `\`) {
+		return "", false, fmt.Errorf("%w: %q", ErrUnsafeArchivePath, rawName)
+	}
+	return name, true, nil
+}
+
+func extractTarball(r io.Reader, sink archiveSink, limits ArchiveLimits) error {
+	if limits.MaxArchiveSize > 0 {
+		r = &limitedReader{r: r, left: limits.MaxArchiveSize}
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("error while reading tarball: %w", err)
+	}
+	defer gz.Close()
+
+	ex := &archiveExtractor{sink: sink, limits: limits}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while reading tarball: %w", err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = ex.extract(hdr.Name, true, 0, hdr.ModTime, nil)
+		case tar.TypeReg:
+			err = ex.extract(hdr.Name, false, hdr.FileInfo().Mode(), hdr.ModTime, tr)
+		default:
+			// pax headers, symlinks, etc.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZipball(r io.ReaderAt, size int64, sink archiveSink, limits ArchiveLimits) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("error while reading zipball: %w", err)
+	}
+
+	ex := &archiveExtractor{sink: sink, limits: limits}
+	for _, file := range zr.File {
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			err = ex.extract(file.Name, true, 0, file.Modified, nil)
+		case mode.IsRegular():
+			err = extractZipFile(ex, file)
+		default:
+			// symlinks, etc.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(ex *archiveExtractor, file *zip.File) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("error while reading zipball: %w", err)
+	}
+	defer rc.Close()
+	return ex.extract(file.Name, false, file.Mode(), file.Modified, rc)
+}
+
+// limitedReader is like io.LimitedReader, but returns ErrArchiveTooLarge
+// instead of io.EOF when the limit is exceeded.
+type limitedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.left <= 0 {
+		return 0, ErrArchiveTooLarge
+	}
+	if int64(len(p)) > lr.left {
+		p = p[:lr.left]
+	}
+	n, err := lr.r.Read(p)
+	lr.left -= int64(n)
+	return n, err
+}
+
+type dirSink struct {
+	dir string
+}
+
+func (s *dirSink) mkdir(name string) error {
+	return os.MkdirAll(filepath.Join(s.dir, filepath.FromSlash(name)), 0755)
+}
+
+func (s *dirSink) writeFile(name string, mode fs.FileMode, modTime time.Time, r io.Reader, max int64) (int64, error) {
+	dst := filepath.Join(s.dir, filepath.FromSlash(name))
+	err := os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return 0, err
+	}
+
+	perm := fs.FileMode(0644)
+	if mode&0111 != 0 {
+		perm = 0755
+	}
+	file, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := copyLimited(file, r, max)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, os.Chtimes(dst, modTime, modTime)
+}
+
+type memSink struct {
+	fs memFS
+}
+
+func (s *memSink) mkdir(name string) error {
+	s.fs.add(&memFile{name: name, mode: fs.ModeDir | 0755})
+	return nil
+}
+
+func (s *memSink) writeFile(name string, mode fs.FileMode, modTime time.Time, r io.Reader, max int64) (int64, error) {
+	buf := new(bytes.Buffer)
+	n, err := copyLimited(buf, r, max)
+	if err != nil {
+		return n, err
+	}
+	s.fs.add(&memFile{
+		name:    name,
+		data:    buf.Bytes(),
+		mode:    mode.Perm(),
+		modTime: modTime,
+	})
+	return n, nil
+}
+
+// memFS is an in-memory fs.FS; the keys are the slash-separated
+// paths of the files and dirs (without the root ".").
+type memFS map[string]*memFile
+
+// add adds the file, and all its missing parent dirs.
+func (m memFS) add(file *memFile) {
+	m[file.name] = file
+	for dir := path.Dir(file.name); dir != "."; dir = path.Dir(dir) {
+		if _, ok := m[dir]; ok {
+			break
+		}
+		m[dir] = &memFile{name: dir, mode: fs.ModeDir | 0755}
+	}
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	file, ok := m[name]
+	if name == "." {
+		file, ok = &memFile{name: ".", mode: fs.ModeDir | 0755}, true
+	}
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if !file.IsDir() {
+		return &openMemFile{memFile: file, Reader: bytes.NewReader(file.data)}, nil
+	}
+
+	var entries []fs.DirEntry
+	for key, child := range m {
+		if path.Dir(key) == name {
+			entries = append(entries, child)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return &openMemDir{memFile: file, entries: entries}, nil
+}
+
+// memFile is a file or dir of a memFS; it is both
+// its own fs.FileInfo and fs.DirEntry.
+type memFile struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (f *memFile) Name() string               { return path.Base(f.name) }
+func (f *memFile) Size() int64                { return int64(len(f.data)) }
+func (f *memFile) Mode() fs.FileMode          { return f.mode }
+func (f *memFile) ModTime() time.Time         { return f.modTime }
+func (f *memFile) IsDir() bool                { return f.mode.IsDir() }
+func (f *memFile) Sys() interface{}           { return nil }
+func (f *memFile) Type() fs.FileMode          { return f.mode.Type() }
+func (f *memFile) Info() (fs.FileInfo, error) { return f, nil }
+
+type openMemFile struct {
+	*memFile
+	*bytes.Reader
+}
+
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.memFile, nil }
+func (f *openMemFile) Close() error               { return nil }
+
+type openMemDir struct {
+	*memFile
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openMemDir) Stat() (fs.FileInfo, error) { return d.memFile, nil }
+func (d *openMemDir) Close() error               { return nil }
+
+func (d *openMemDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *openMemDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n > 0 && len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(remaining) {
+		remaining = remaining[:n]
+	}
+	d.offset += len(remaining)
+	return remaining, nil
+}
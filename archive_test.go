@@ -0,0 +1,232 @@
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestStripArchivePrefix(t *testing.T) {
+	tests := []struct {
+		rawName string
+		name    string
+		ok      bool
+		unsafe  bool
+	}{
+		{rawName: "owner-repo-sha/", ok: false},
+		{rawName: "owner-repo-sha", ok: false},
+		{rawName: "owner-repo-sha/README.md", name: "README.md", ok: true},
+		{rawName: "owner-repo-sha/dir/", name: "dir", ok: true},
+		{rawName: "owner-repo-sha/dir/file.go", name: "dir/file.go", ok: true},
+
+		{rawName: "owner-repo-sha/../evil", unsafe: true},
+		{rawName: "owner-repo-sha/dir/../../evil", unsafe: true},
+		{rawName: "owner-repo-sha/./file", unsafe: true},
+		{rawName: "owner-repo-sha//file", unsafe: true},
+		{rawName: `owner-repo-sha/dir\..\..\evil`, unsafe: true},
+		{rawName: "../evil", unsafe: true},
+		{rawName: "..", unsafe: true},
+		{rawName: "./file", unsafe: true},
+		{rawName: "/etc/passwd", unsafe: true},
+		{rawName: "/", ok: false},
+		{rawName: `C:\evil`, unsafe: true},
+		{rawName: `owner-repo-sha\..\evil`, unsafe: true},
+	}
+	for _, tt := range tests {
+		name, ok, err := stripArchivePrefix(tt.rawName)
+		if tt.unsafe {
+			if !errors.Is(err, ErrUnsafeArchivePath) {
+				t.Errorf("stripArchivePrefix(%q): expected ErrUnsafeArchivePath, got (%q, %v, %v)", tt.rawName, name, ok, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("stripArchivePrefix(%q): unexpected error: %v", tt.rawName, err)
+			continue
+		}
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("stripArchivePrefix(%q) = (%q, %v), expected (%q, %v)", tt.rawName, name, ok, tt.name, tt.ok)
+		}
+	}
+}
+
+type testArchiveEntry struct {
+	name string
+	body string
+}
+
+func buildTestTarball(t *testing.T, entries []testArchiveEntry) []byte {
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(entry.body)),
+			ModTime:  time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTestZipball(t *testing.T, entries []testArchiveEntry) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, entry := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testArchiveEntry
+		unsafe  bool
+	}{
+		{
+			name: "safe",
+			entries: []testArchiveEntry{
+				{name: "owner-repo-sha/README.md", body: "hello"},
+				{name: "owner-repo-sha/dir/file.go", body: "package dir"},
+			},
+		},
+		{
+			name: "parent dir",
+			entries: []testArchiveEntry{
+				{name: "owner-repo-sha/../../evil", body: "evil"},
+			},
+			unsafe: true,
+		},
+		{
+			name: "parent prefix",
+			entries: []testArchiveEntry{
+				{name: "../evil", body: "evil"},
+			},
+			unsafe: true,
+		},
+		{
+			name: "absolute",
+			entries: []testArchiveEntry{
+				{name: "/owner-repo-sha/evil", body: "evil"},
+			},
+			unsafe: true,
+		},
+		{
+			name: "backslash",
+			entries: []testArchiveEntry{
+				{name: `owner-repo-sha/..\..\evil`, body: "evil"},
+			},
+			unsafe: true,
+		},
+		{
+			name: "unsafe after safe",
+			entries: []testArchiveEntry{
+				{name: "owner-repo-sha/README.md", body: "hello"},
+				{name: "owner-repo-sha/dir/../../../evil", body: "evil"},
+			},
+			unsafe: true,
+		},
+	}
+	for _, tt := range tests {
+		for _, format := range []ArchiveFormat{ArchiveTarball, ArchiveZipball} {
+			root := t.TempDir()
+			dir := filepath.Join(root, "out")
+			sink := &dirSink{dir: dir}
+
+			var err error
+			switch format {
+			case ArchiveTarball:
+				err = extractTarball(bytes.NewReader(buildTestTarball(t, tt.entries)), sink, DefaultArchiveLimits)
+			case ArchiveZipball:
+				data := buildTestZipball(t, tt.entries)
+				err = extractZipball(bytes.NewReader(data), int64(len(data)), sink, DefaultArchiveLimits)
+			}
+
+			if tt.unsafe {
+				if !errors.Is(err, ErrUnsafeArchivePath) {
+					t.Errorf("%s (%s): expected ErrUnsafeArchivePath, got %v", tt.name, format, err)
+				}
+			} else if err != nil {
+				t.Errorf("%s (%s): unexpected error: %v", tt.name, format, err)
+			}
+
+			// nothing must be written outside of the extraction dir.
+			outside, err := filepath.Glob(filepath.Join(root, "*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range outside {
+				if path != dir {
+					t.Errorf("%s (%s): file written outside of the extraction dir: %s", tt.name, format, path)
+				}
+			}
+		}
+	}
+}
+
+func TestExtractToFS(t *testing.T) {
+	entries := []testArchiveEntry{
+		{name: "owner-repo-sha/README.md", body: "hello"},
+		{name: "owner-repo-sha/dir/file.go", body: "package dir"},
+		{name: "owner-repo-sha/dir/sub/other.go", body: "package sub"},
+	}
+	for _, format := range []ArchiveFormat{ArchiveTarball, ArchiveZipball} {
+		var data []byte
+		switch format {
+		case ArchiveTarball:
+			data = buildTestTarball(t, entries)
+		case ArchiveZipball:
+			data = buildTestZipball(t, entries)
+		}
+		archive := &RepoArchive{
+			format: format,
+			body:   ioutil.NopCloser(bytes.NewReader(data)),
+		}
+		fsys, err := archive.ExtractToFS(nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if err := fstest.TestFS(fsys, "README.md", "dir/file.go", "dir/sub/other.go"); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+		content, err := fs.ReadFile(fsys, "dir/sub/other.go")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if string(content) != "package sub" {
+			t.Errorf("%s: content = %q, want %q", format, content, "package sub")
+		}
+	}
+}
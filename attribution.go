@@ -0,0 +1,429 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+const (
+	TrailerCoAuthoredBy = "Co-authored-by"
+	TrailerSignedOffBy  = "Signed-off-by"
+)
+
+// CommitTrailer is a trailer of a commit message (e.g. `Co-authored-by: Name <email>`).
+type CommitTrailer struct {
+	Key   string
+	Value string
+}
+
+// CommitPerson is a person mentioned in a commit.
+type CommitPerson struct {
+	Name  string
+	Email string
+	// Login is the GitHub login; empty if it could not be resolved.
+	Login string
+}
+
+// AttributedCommit is a commit with everyone who contributed to it.
+type AttributedCommit struct {
+	*github.RepositoryCommit
+
+	Trailers []CommitTrailer
+
+	Author    *CommitPerson
+	Committer *CommitPerson
+	// CoAuthors are from the Co-authored-by trailers.
+	CoAuthors []*CommitPerson
+	// SignedOffBy are from the Signed-off-by trailers.
+	SignedOffBy []*CommitPerson
+	// MalformedTrailers are the lines of the trailer block that could not be parsed,
+	// including the Co-authored-by and Signed-off-by trailers without a valid `Name <email>`;
+	// the people in them are missing from CoAuthors and SignedOffBy.
+	MalformedTrailers []string
+}
+
+// Logins returns the (deduplicated) logins of the author and co-authors
+// of the commit; the committer and the signers are not included.
+func (ac *AttributedCommit) Logins() []string {
+	var logins []string
+	seen := make(map[string]bool)
+	people := append([]*CommitPerson{ac.Author}, ac.CoAuthors...)
+	for _, person := range people {
+		if person == nil || person.Login == "" {
+			continue
+		}
+		key := strings.ToLower(person.Login)
+		if !seen[key] {
+			seen[key] = true
+			logins = append(logins, person.Login)
+		}
+	}
+	return logins
+}
+
+// trailerRegex matches a trailer line; the key is a token without spaces.
+var trailerRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.+)$`)
+
+// ParseCommitTrailers parses the trailers of a commit message,
+// i.e. the `Key: value` lines of the last paragraph.
+// See parseCommitTrailers for the lines of the trailer block that are not trailers.
+func ParseCommitTrailers(message string) []CommitTrailer {
+	trailers, _ := parseCommitTrailers(message)
+	return trailers
+}
+
+// parseCommitTrailers parses the trailers of a commit message,
+// and returns also the lines of the trailer block that are not valid trailers.
+// Like git, the last paragraph is a trailer block if all its lines are trailers,
+// or if it has a Co-authored-by or Signed-off-by trailer and at least 25% of its lines are trailers;
+// lines starting with whitespace continue the value of the previous trailer.
+func parseCommitTrailers(message string) ([]CommitTrailer, []string) {
+	message = strings.TrimRight(strings.Replace(message, "\r\n", "\n", -1), "\n ")
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		// a message with only one paragraph has only the subject.
+		return nil, nil
+	}
+	last := paragraphs[len(paragraphs)-1]
+
+	var trailers []CommitTrailer
+	var malformed []string
+	var lines int
+	var known bool
+	for _, line := range strings.Split(last, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(trailers) > 0 {
+			// continuation of the previous trailer.
+			previous := &trailers[len(trailers)-1]
+			previous.Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		lines++
+		line = strings.TrimSpace(line)
+		match := trailerRegex.FindStringSubmatch(line)
+		if match == nil {
+			malformed = append(malformed, line)
+			continue
+		}
+		if strings.EqualFold(match[1], TrailerCoAuthoredBy) || strings.EqualFold(match[1], TrailerSignedOffBy) {
+			known = true
+		}
+		trailers = append(trailers, CommitTrailer{
+			Key:   match[1],
+			Value: strings.TrimSpace(match[2]),
+		})
+	}
+	if len(malformed) == 0 {
+		return trailers, nil
+	}
+	if !known || len(trailers)*4 < lines {
+		// not a trailer block.
+		return nil, nil
+	}
+	return trailers, malformed
+}
+
+// ParseCommitPerson parses a `Name <email>` value.
+func ParseCommitPerson(value string) (*CommitPerson, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid person %q: %w", value, err)
+	}
+	return &CommitPerson{
+		Name:  addr.Name,
+		Email: addr.Address,
+	}, nil
+}
+
+// noreplyRegex matches the GitHub noreply addresses:
+// `ID+login@users.noreply.github.com` and `login@users.noreply.github.com`.
+var noreplyRegex = regexp.MustCompile(`(?i)^(?:\d+\+)?([a-z0-9](?:[a-z0-9-]*[a-z0-9])?)@users\.noreply\.github\.com$`)
+
+// LoginFromNoreplyEmail returns the login of a GitHub noreply address.
+func LoginFromNoreplyEmail(email string) (string, bool) {
+	match := noreplyRegex.FindStringSubmatch(email)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// commitLogins returns the logins of the author and of the committer of the commit;
+// when the email of one of them is not linked to a user,
+// the login is taken from the noreply address, if any (no request is done).
+func commitLogins(commit *github.RepositoryCommit) (string, string) {
+	author := commit.GetAuthor().GetLogin()
+	if author == "" {
+		author, _ = LoginFromNoreplyEmail(commit.GetCommit().GetAuthor().GetEmail())
+	}
+	committer := commit.GetCommitter().GetLogin()
+	if committer == "" {
+		committer, _ = LoginFromNoreplyEmail(commit.GetCommit().GetCommitter().GetEmail())
+	}
+	return author, committer
+}
+
+// AttributeCommit parses the trailers of the commit, and resolves
+// the emails of all the people to GitHub logins.
+// If resolve is false, only the logins that don't need
+// any request are resolved (linked users and noreply addresses).
+func (c *Client) AttributeCommit(commit *github.RepositoryCommit, resolve bool) (*AttributedCommit, error) {
+	trailers, malformed := parseCommitTrailers(commit.GetCommit().GetMessage())
+	ac := &AttributedCommit{
+		RepositoryCommit:  commit,
+		Trailers:          trailers,
+		MalformedTrailers: malformed,
+		Author: &CommitPerson{
+			Name:  commit.GetCommit().GetAuthor().GetName(),
+			Email: commit.GetCommit().GetAuthor().GetEmail(),
+			Login: commit.GetAuthor().GetLogin(),
+		},
+		Committer: &CommitPerson{
+			Name:  commit.GetCommit().GetCommitter().GetName(),
+			Email: commit.GetCommit().GetCommitter().GetEmail(),
+			Login: commit.GetCommitter().GetLogin(),
+		},
+	}
+	for _, trailer := range ac.Trailers {
+		var dst *[]*CommitPerson
+		switch {
+		case strings.EqualFold(trailer.Key, TrailerCoAuthoredBy):
+			dst = &ac.CoAuthors
+		case strings.EqualFold(trailer.Key, TrailerSignedOffBy):
+			dst = &ac.SignedOffBy
+		default:
+			continue
+		}
+		person, err := ParseCommitPerson(trailer.Value)
+		if err != nil {
+			// malformed trailers are common: report them, but don't fail.
+			ac.MalformedTrailers = append(ac.MalformedTrailers, trailer.Key+": "+trailer.Value)
+			continue
+		}
+		*dst = append(*dst, person)
+	}
+
+	people := []*CommitPerson{ac.Author, ac.Committer}
+	people = append(people, ac.CoAuthors...)
+	people = append(people, ac.SignedOffBy...)
+	for _, person := range people {
+		if person.Login != "" || person.Email == "" {
+			continue
+		}
+		if login, ok := LoginFromNoreplyEmail(person.Email); ok {
+			person.Login = login
+			continue
+		}
+		if !resolve {
+			continue
+		}
+		login, err := c.ResolveEmailToLogin(person.Email)
+		if err != nil {
+			return nil, fmt.Errorf("error while resolving %s: %w", person.Email, err)
+		}
+		person.Login = login
+	}
+
+	return ac, nil
+}
+
+// ListAttributedCommits is like ListCommits, but the commits
+// come with the parsed trailers and the resolved logins of everyone involved;
+// see ListCommitsOpts.OnAttributed.
+func (c *Client) ListAttributedCommits(
+	owner string,
+	repo string,
+	opts *ListCommitsOpts,
+	resolve bool,
+) ([]*AttributedCommit, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	var attributed []*AttributedCommit
+	withAttribution := *opts
+	withAttribution.ResolveLogins = resolve
+	withAttribution.OnAttributed = func(commit *AttributedCommit) {
+		attributed = append(attributed, commit)
+		if opts.OnAttributed != nil {
+			opts.OnAttributed(commit)
+		}
+	}
+	_, err := c.ListCommits(owner, repo, &withAttribution)
+	if err != nil {
+		return nil, err
+	}
+	return attributed, nil
+}
+
+// ResolveEmailToLogin returns the GitHub login of the user with the email;
+// empty if not found.
+// Noreply addresses are resolved without requests; other emails
+// are looked up in the commit search (author-email), then in the user search
+// (only public emails); results are cached in the Client.
+func (c *Client) ResolveEmailToLogin(email string) (string, error) {
+	if login, ok := LoginFromNoreplyEmail(email); ok {
+		return login, nil
+	}
+	key := strings.ToLower(email)
+
+	c.emailLoginsMu.Lock()
+	login, ok := c.emailLogins[key]
+	c.emailLoginsMu.Unlock()
+	if ok {
+		return login, nil
+	}
+
+	login, err := c.searchLoginByCommitEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if login == "" {
+		login, err = c.searchLoginByUserEmail(email)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	c.emailLoginsMu.Lock()
+	if c.emailLogins == nil {
+		c.emailLogins = make(map[string]string)
+	}
+	c.emailLogins[key] = login
+	c.emailLoginsMu.Unlock()
+
+	return login, nil
+}
+
+func (c *Client) searchLoginByCommitEmail(email string) (string, error) {
+	client := c.client
+
+	query := Sf("author-email:%s", email)
+	opt := &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 1},
+	}
+
+	var result *github.CommitsSearchResult
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		result, resp, err = client.Search.Commits(ctx, query, opt)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			// TODO: catch rate limit error, and wait
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		return "", errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+
+	for _, commit := range result.Commits {
+		if login := commit.GetAuthor().GetLogin(); login != "" {
+			return login, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *Client) searchLoginByUserEmail(email string) (string, error) {
+	client := c.client
+
+	query := Sf("%s in:email", email)
+	opt := &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 2},
+	}
+
+	var result *github.UsersSearchResult
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		result, resp, err = client.Search.Users(ctx, query, opt)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			// TODO: catch rate limit error, and wait
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		return "", errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+
+	// Only an unambiguous match is trusted.
+	if len(result.Users) != 1 {
+		return "", nil
+	}
+	return result.Users[0].GetLogin(), nil
+}
+
+// IsDirect tells whether the commit was committed by its author
+// (like isDirectCommit, but using also the logins resolved with ResolveEmailToLogin).
+func (ac *AttributedCommit) IsDirect() bool {
+	return ac.Author.Login != "" && strings.EqualFold(ac.Author.Login, ac.Committer.Login)
+}
+
+// FindDirectContributors returns the logins (with their commits)
+// of everyone who contributed to a direct commit,
+// including the co-authors, which isDirectCommit alone does not see.
+func FindDirectContributors(commits []*AttributedCommit) map[string][]*AttributedCommit {
+	contributors := make(map[string][]*AttributedCommit)
+	for _, commit := range commits {
+		if !commit.IsDirect() {
+			continue
+		}
+		for _, login := range commit.Logins() {
+			contributors[login] = append(contributors[login], commit)
+		}
+	}
+	return contributors
+}
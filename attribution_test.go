@@ -0,0 +1,265 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestParseCommitTrailers(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		trailers  []CommitTrailer
+		malformed []string
+	}{
+		{
+			name:    "subject only",
+			message: "Fix bug",
+		},
+		{
+			name:    "subject only with trailer-like subject",
+			message: "Co-authored-by: Alice <alice@example.com>",
+		},
+		{
+			name:    "no trailers",
+			message: "Fix bug\n\nThis fixes the bug.",
+		},
+		{
+			name:    "co-author",
+			message: "Fix bug\n\nCo-authored-by: Alice <alice@example.com>\n",
+			trailers: []CommitTrailer{
+				{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+			},
+		},
+		{
+			name:    "crlf and many trailers",
+			message: "Fix bug\r\n\r\nBody.\r\n\r\nCo-authored-by: Alice <alice@example.com>\r\nSigned-off-by: Bob <bob@example.com>\r\n",
+			trailers: []CommitTrailer{
+				{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+				{Key: "Signed-off-by", Value: "Bob <bob@example.com>"},
+			},
+		},
+		{
+			name:    "continuation line",
+			message: "Fix bug\n\nCo-authored-by: Alice\n  <alice@example.com>",
+			trailers: []CommitTrailer{
+				{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+			},
+		},
+		{
+			name:    "malformed line in trailer block",
+			message: "Fix bug\n\nCo-authored-by: Alice <alice@example.com>\nCo-authored-by Bob <bob@example.com>",
+			trailers: []CommitTrailer{
+				{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+			},
+			malformed: []string{"Co-authored-by Bob <bob@example.com>"},
+		},
+		{
+			name:    "key with spaces",
+			message: "Fix bug\n\nSigned-off-by: Bob <bob@example.com>\nCo authored by: Alice <alice@example.com>",
+			trailers: []CommitTrailer{
+				{Key: "Signed-off-by", Value: "Bob <bob@example.com>"},
+			},
+			malformed: []string{"Co authored by: Alice <alice@example.com>"},
+		},
+		{
+			name:    "prose ending with a colon line",
+			message: "Fix bug\n\nThis is a long explanation\nthat spans lines\nand more lines\nand ends with\nNote: something",
+		},
+		{
+			name:    "empty value",
+			message: "Fix bug\n\nCo-authored-by:",
+		},
+		{
+			name:    "trailing whitespace",
+			message: "Fix bug\n\nCo-authored-by:   Alice <alice@example.com>   \n\n\n",
+			trailers: []CommitTrailer{
+				{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		trailers, malformed := parseCommitTrailers(tt.message)
+		if !reflect.DeepEqual(trailers, tt.trailers) {
+			t.Errorf("%s: trailers = %#v, expected %#v", tt.name, trailers, tt.trailers)
+		}
+		if !reflect.DeepEqual(malformed, tt.malformed) {
+			t.Errorf("%s: malformed = %#v, expected %#v", tt.name, malformed, tt.malformed)
+		}
+		if public := ParseCommitTrailers(tt.message); !reflect.DeepEqual(public, tt.trailers) {
+			t.Errorf("%s: ParseCommitTrailers = %#v, expected %#v", tt.name, public, tt.trailers)
+		}
+	}
+}
+
+func TestParseCommitPerson(t *testing.T) {
+	tests := []struct {
+		value string
+		name  string
+		email string
+		err   bool
+	}{
+		{value: "Alice <alice@example.com>", name: "Alice", email: "alice@example.com"},
+		{value: "Alice Smith <alice@example.com>", name: "Alice Smith", email: "alice@example.com"},
+		{value: "<alice@example.com>", email: "alice@example.com"},
+		{value: "alice@example.com", email: "alice@example.com"},
+		{value: "Alice", err: true},
+		{value: "Alice <alice>", err: true},
+		{value: "Alice <alice@example.com", err: true},
+		{value: "", err: true},
+	}
+	for _, tt := range tests {
+		person, err := ParseCommitPerson(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseCommitPerson(%q): expected an error, got %+v", tt.value, person)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCommitPerson(%q): unexpected error: %v", tt.value, err)
+			continue
+		}
+		if person.Name != tt.name || person.Email != tt.email {
+			t.Errorf("ParseCommitPerson(%q) = (%q, %q), expected (%q, %q)", tt.value, person.Name, person.Email, tt.name, tt.email)
+		}
+	}
+}
+
+func TestLoginFromNoreplyEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		login string
+		ok    bool
+	}{
+		{email: "12345+alice@users.noreply.github.com", login: "alice", ok: true},
+		{email: "alice@users.noreply.github.com", login: "alice", ok: true},
+		{email: "Alice-Bob@Users.Noreply.GitHub.com", login: "Alice-Bob", ok: true},
+		{email: "alice@example.com"},
+		{email: "-alice@users.noreply.github.com"},
+		{email: "alice-@users.noreply.github.com"},
+		{email: "alice@users.noreply.github.com.evil.com"},
+		{email: "+alice@users.noreply.github.com"},
+		{email: ""},
+	}
+	for _, tt := range tests {
+		login, ok := LoginFromNoreplyEmail(tt.email)
+		if login != tt.login || ok != tt.ok {
+			t.Errorf("LoginFromNoreplyEmail(%q) = (%q, %v), expected (%q, %v)", tt.email, login, ok, tt.login, tt.ok)
+		}
+	}
+}
+
+func TestAttributeCommitMalformedTrailers(t *testing.T) {
+	message := "Fix bug\n\nCo-authored-by: Alice <alice@example.com>\nCo-authored-by: Bob\nSigned-off-by: 1+carol@users.noreply.github.com"
+	commit := &github.RepositoryCommit{
+		Commit: &github.Commit{Message: &message},
+	}
+	ac, err := (&Client{}).AttributeCommit(commit, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac.CoAuthors) != 1 || ac.CoAuthors[0].Email != "alice@example.com" {
+		t.Errorf("CoAuthors = %+v", ac.CoAuthors)
+	}
+	if len(ac.SignedOffBy) != 1 || ac.SignedOffBy[0].Login != "carol" {
+		t.Errorf("SignedOffBy = %+v", ac.SignedOffBy)
+	}
+	expected := []string{"Co-authored-by: Bob"}
+	if !reflect.DeepEqual(ac.MalformedTrailers, expected) {
+		t.Errorf("MalformedTrailers = %#v, expected %#v", ac.MalformedTrailers, expected)
+	}
+}
+
+func TestIsDirectCommit(t *testing.T) {
+	user := func(login string) *github.User {
+		return &github.User{Login: &login}
+	}
+	person := func(email string) *github.CommitAuthor {
+		return &github.CommitAuthor{Email: &email}
+	}
+	// oldIsDirect is the rule isDirectCommit used to follow.
+	oldIsDirect := func(commit *github.RepositoryCommit) bool {
+		return commit.Author.GetLogin() == commit.Committer.GetLogin()
+	}
+	tests := []struct {
+		name   string
+		commit *github.RepositoryCommit
+		old    bool
+		direct bool
+	}{
+		{
+			name:   "same login",
+			commit: &github.RepositoryCommit{Author: user("alice"), Committer: user("alice")},
+			old:    true,
+			direct: true,
+		},
+		{
+			name:   "different logins",
+			commit: &github.RepositoryCommit{Author: user("alice"), Committer: user("bob")},
+		},
+		{
+			name:   "same login, different case",
+			commit: &github.RepositoryCommit{Author: user("Alice"), Committer: user("alice")},
+			direct: true,
+		},
+		{
+			name: "both unlinked",
+			commit: &github.RepositoryCommit{
+				Commit: &github.Commit{Author: person("alice@example.com"), Committer: person("bob@example.com")},
+			},
+			old: true,
+		},
+		{
+			name: "unlinked noreply committer",
+			commit: &github.RepositoryCommit{
+				Author: user("alice"),
+				Commit: &github.Commit{Author: person("alice@example.com"), Committer: person("1+alice@users.noreply.github.com")},
+			},
+			direct: true,
+		},
+	}
+	for _, tt := range tests {
+		if old := oldIsDirect(tt.commit); old != tt.old {
+			t.Errorf("%s: old rule = %v, expected %v", tt.name, old, tt.old)
+		}
+		if direct := isDirectCommit(tt.commit); direct != tt.direct {
+			t.Errorf("%s: isDirectCommit = %v, expected %v", tt.name, direct, tt.direct)
+		}
+	}
+}
+
+func TestListCommitsOnAttributed(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"sha": "1", "commit": {"message": "Fix bug\n\nCo-authored-by: Bob <1+bob@users.noreply.github.com>"}, "author": {"login": "alice"}},
+			{"sha": "2", "commit": {"message": "Add feature"}, "author": {"login": "alice"}}
+		]`)
+	}))
+
+	var attributed []*AttributedCommit
+	commits, err := client.ListCommits("owner", "repo", &ListCommitsOpts{
+		OnAttributed: func(commit *AttributedCommit) {
+			attributed = append(attributed, commit)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || len(attributed) != 2 {
+		t.Fatalf("got %d commits and %d attributed commits, expected 2 and 2", len(commits), len(attributed))
+	}
+	if attributed[0].RepositoryCommit != commits[0] {
+		t.Errorf("attributed commit is not the listed one")
+	}
+	if len(attributed[0].CoAuthors) != 1 || attributed[0].CoAuthors[0].Login != "bob" {
+		t.Errorf("co-authors = %+v, expected bob", attributed[0].CoAuthors)
+	}
+	if len(attributed[1].CoAuthors) != 0 {
+		t.Errorf("co-authors = %+v, expected none", attributed[1].CoAuthors)
+	}
+}
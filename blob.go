@@ -0,0 +1,125 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// BlobHashMismatchError is returned when the git blob SHA-1
+// of the downloaded content is not the requested one.
+type BlobHashMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *BlobHashMismatchError) Error() string {
+	return fmt.Sprintf("blob hash mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// GitBlobSHA1 returns the hex-encoded git object ID
+// of a blob with the provided content.
+func GitBlobSHA1(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DownloadBlob downloads the blob with the provided SHA using the Git Blobs API,
+// which (unlike the Contents API) supports files up to 100MB.
+// The git blob SHA-1 of the downloaded content is verified
+// and a *BlobHashMismatchError is returned if it does not match.
+func (r *RepoExplorationRequest) DownloadBlob(sha string) (io.ReadCloser, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if sha == "" {
+		return nil, errors.New("sha not provided")
+	}
+	if r.client.blobCache != nil {
+		if cached, ok := r.client.blobCache.Get(sha); ok {
+			return cached, nil
+		}
+	}
+
+	var content []byte
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		content, resp, err = r.client.client.Git.GetBlobRaw(ctx, r.params.owner, r.params.repo, sha)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			// TODO: catch rate limit error, and wait
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	actual := GitBlobSHA1(content)
+	if !strings.EqualFold(actual, sha) {
+		return nil, &BlobHashMismatchError{
+			Expected: sha,
+			Actual:   actual,
+		}
+	}
+
+	if r.client.blobCache != nil {
+		// the content is already downloaded: a failure
+		// while caching it is not a failure of the download.
+		r.client.blobCache.Put(sha, content)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+// downloadFileViaBlob downloads the file at the path using its blob SHA.
+// Symlinks, submodules and anything else that is not a regular file
+// are downloaded with the Contents API, as DownloadContents does.
+func (r *RepoExplorationRequest) downloadFileViaBlob(filepath string) (io.ReadCloser, error) {
+	fileContent, _, _, err := r.client.client.Repositories.GetContents(context.Background(), r.params.owner, r.params.repo, filepath, r.contentGetOptions())
+	if err != nil {
+		return nil, err
+	}
+	if fileContent == nil || GetContentKind(fileContent) != KindFile || fileContent.GetSHA() == "" {
+		return r.client.client.Repositories.DownloadContents(context.Background(), r.params.owner, r.params.repo, filepath, r.contentGetOptions())
+	}
+	return r.DownloadBlob(fileContent.GetSHA())
+}
@@ -0,0 +1,369 @@
+package github
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlobCache is an on-disk content-addressed cache of blobs, keyed by their SHA.
+// The same cache dir can be shared by multiple processes:
+// entries are written atomically (write to a temp file, then rename),
+// and are verified against their key when read, so a broken entry
+// is never returned (it is removed instead).
+// When the total size exceeds the max size, the least recently used
+// entries are evicted.
+type BlobCache struct {
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+const (
+	blobCacheKindGit = "git"
+	blobCacheKindLFS = "lfs"
+)
+
+// NewBlobCache opens (or creates) a blob cache in the dir.
+// A maxSize <= 0 means no size limit.
+func NewBlobCache(dir string, maxSize int64) (*BlobCache, error) {
+	if dir == "" {
+		return nil, errors.New("dir not provided")
+	}
+	for _, kind := range []string{blobCacheKindGit, blobCacheKindLFS} {
+		err := os.MkdirAll(filepath.Join(dir, kind), 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cache := &BlobCache{
+		dir:     dir,
+		maxSize: maxSize,
+	}
+	entries, err := cache.scan()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		cache.size += entry.size
+	}
+	return cache, nil
+}
+
+// SetBlobCache sets the cache that is consulted before downloading
+// any blob with a RepoExplorationRequest; nil disables the cache.
+func (c *Client) SetBlobCache(cache *BlobCache) {
+	c.blobCache = cache
+}
+
+func (bc *BlobCache) pathOf(kind string, key string) (string, error) {
+	key = strings.ToLower(key)
+	if len(key) < 3 {
+		return "", fmt.Errorf("invalid cache key: %q", key)
+	}
+	if _, err := hex.DecodeString(key); err != nil {
+		return "", fmt.Errorf("invalid cache key: %q", key)
+	}
+	return filepath.Join(bc.dir, kind, key[:2], key[2:]), nil
+}
+
+// Get returns the content of the git blob with the provided SHA, if cached.
+// The caller must close the returned reader.
+func (bc *BlobCache) Get(sha string) (io.ReadCloser, bool) {
+	file, ok := bc.get(blobCacheKindGit, sha)
+	if !ok {
+		return nil, false
+	}
+	return file, true
+}
+
+// Put adds the content of the git blob with the provided SHA to the cache.
+func (bc *BlobCache) Put(sha string, content []byte) error {
+	return bc.put(blobCacheKindGit, sha, content)
+}
+
+// get opens the entry, after verifying it by streaming it through the hash,
+// so that large entries (e.g. LFS objects) are never held in memory.
+func (bc *BlobCache) get(kind string, key string) (*os.File, bool) {
+	p, err := bc.pathOf(kind, key)
+	if err != nil {
+		return nil, false
+	}
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, false
+	}
+	h := blobCacheHasher(kind, file)
+	_, err = io.Copy(h, file)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, false
+	}
+	if !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), key) {
+		// broken entry (e.g. disk corruption).
+		file.Close()
+		os.Remove(p)
+		return nil, false
+	}
+	// mark as recently used:
+	now := time.Now()
+	os.Chtimes(p, now, now)
+	return file, true
+}
+
+func (bc *BlobCache) put(kind string, key string, content []byte) error {
+	if !strings.EqualFold(blobCacheHash(kind, content), key) {
+		return &BlobHashMismatchError{
+			Expected: key,
+			Actual:   blobCacheHash(kind, content),
+		}
+	}
+	p, err := bc.pathOf(kind, key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(p); err == nil {
+		// already cached (maybe by another process).
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	bc.mu.Lock()
+	bc.size += int64(len(content))
+	needsEviction := bc.maxSize > 0 && bc.size > bc.maxSize
+	bc.mu.Unlock()
+
+	if needsEviction {
+		return bc.evict()
+	}
+	return nil
+}
+
+// blobCacheHasher returns the hash of the entries of the kind;
+// for git blobs, the header is written using the size of the file.
+func blobCacheHasher(kind string, file *os.File) hash.Hash {
+	if kind == blobCacheKindLFS {
+		return sha256.New()
+	}
+	h := sha1.New()
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	fmt.Fprintf(h, "blob %d\x00", size)
+	return h
+}
+
+func blobCacheHash(kind string, content []byte) string {
+	if kind == blobCacheKindLFS {
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:])
+	}
+	return GitBlobSHA1(content)
+}
+
+type blobCacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (bc *BlobCache) scan() ([]blobCacheEntry, error) {
+	var entries []blobCacheEntry
+	err := filepath.Walk(bc.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				// removed by another process meanwhile.
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		entries = append(entries, blobCacheEntry{
+			path:    p,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// evict removes the least recently used entries until the size
+// of the cache is below 90% of the max size.
+// The actual size is re-computed from the disk, because other processes
+// might be using the same cache dir.
+func (bc *BlobCache) evict() error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	entries, err := bc.scan()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.size
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	target := bc.maxSize / 10 * 9
+	for _, entry := range entries {
+		if size <= target {
+			break
+		}
+		err := os.Remove(entry.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= entry.size
+	}
+	bc.size = size
+	return nil
+}
+
+// cachingReader stores the LFS object that is read through it into the cache,
+// once it has been read completely and verified (against its sha256).
+type cachingReader struct {
+	rc   io.ReadCloser
+	bc   *BlobCache
+	kind string
+	key  string
+
+	tmp      *os.File
+	hash     hash.Hash
+	size     int64
+	err      error
+	mismatch error
+}
+
+func (bc *BlobCache) newLFSCachingReader(oid string, rc io.ReadCloser) io.ReadCloser {
+	p, err := bc.pathOf(blobCacheKindLFS, oid)
+	if err != nil {
+		return rc
+	}
+	err = os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return rc
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return rc
+	}
+	return &cachingReader{
+		rc:   rc,
+		bc:   bc,
+		kind: blobCacheKindLFS,
+		key:  oid,
+		tmp:  tmp,
+		hash: sha256.New(),
+	}
+}
+
+// Read returns a *BlobHashMismatchError instead of io.EOF
+// if the content does not match the key.
+func (cr *cachingReader) Read(p []byte) (int, error) {
+	n, err := cr.rc.Read(p)
+	if n > 0 {
+		cr.hash.Write(p[:n])
+		cr.size += int64(n)
+		if cr.err == nil && cr.tmp != nil {
+			_, cr.err = cr.tmp.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		if cr.tmp != nil {
+			cr.mismatch = cr.commit()
+		}
+		if cr.mismatch != nil {
+			return n, cr.mismatch
+		}
+	}
+	return n, err
+}
+
+// commit moves the temp file into the cache;
+// a failure to cache is not an error for the reader,
+// but a content that does not match the key is.
+func (cr *cachingReader) commit() error {
+	tmpName := cr.tmp.Name()
+	closeErr := cr.tmp.Close()
+	cr.tmp = nil
+
+	actual := hex.EncodeToString(cr.hash.Sum(nil))
+	if !strings.EqualFold(actual, cr.key) {
+		os.Remove(tmpName)
+		return &BlobHashMismatchError{
+			Expected: cr.key,
+			Actual:   actual,
+		}
+	}
+
+	ok := cr.err == nil && closeErr == nil
+	if ok {
+		p, _ := cr.bc.pathOf(cr.kind, cr.key)
+		ok = os.Rename(tmpName, p) == nil
+	}
+	if !ok {
+		os.Remove(tmpName)
+		return nil
+	}
+
+	cr.bc.mu.Lock()
+	cr.bc.size += cr.size
+	needsEviction := cr.bc.maxSize > 0 && cr.bc.size > cr.bc.maxSize
+	cr.bc.mu.Unlock()
+	if needsEviction {
+		cr.bc.evict()
+	}
+	return nil
+}
+
+func (cr *cachingReader) Close() error {
+	if cr.tmp != nil {
+		// not read completely: discard.
+		cr.tmp.Close()
+		os.Remove(cr.tmp.Name())
+		cr.tmp = nil
+	}
+	return cr.rc.Close()
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestBlobCacheGet(t *testing.T) {
+	cache, err := NewBlobCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("hello world\n")
+	sha := GitBlobSHA1(content)
+	sum := sha256.Sum256(content)
+	oid := hex.EncodeToString(sum[:])
+
+	if err := cache.Put(sha, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.put(blobCacheKindLFS, oid, content); err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{blobCacheKindGit, blobCacheKindLFS} {
+		key := sha
+		if kind == blobCacheKindLFS {
+			key = oid
+		}
+		file, ok := cache.get(kind, key)
+		if !ok {
+			t.Fatalf("%s: entry not found", kind)
+		}
+		got, err := ioutil.ReadAll(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(content) {
+			t.Errorf("%s: content = %q, want %q", kind, got, content)
+		}
+	}
+
+	// a broken entry is removed instead of being returned.
+	p, err := cache.pathOf(blobCacheKindGit, sha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(p, []byte("corrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(sha); ok {
+		t.Errorf("broken entry returned")
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Errorf("broken entry not removed: %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// Permission is a permission level on a repo.
+type Permission string
+
+const (
+	PermissionNone     Permission = "none"
+	PermissionRead     Permission = "read"
+	PermissionTriage   Permission = "triage"
+	PermissionWrite    Permission = "write"
+	PermissionMaintain Permission = "maintain"
+	PermissionAdmin    Permission = "admin"
+)
+
+var permissionRanks = map[Permission]int{
+	PermissionNone:     0,
+	PermissionRead:     1,
+	PermissionTriage:   2,
+	PermissionWrite:    3,
+	PermissionMaintain: 4,
+	PermissionAdmin:    5,
+}
+
+// AtLeast tells whether the permission includes the other one.
+func (p Permission) AtLeast(other Permission) bool {
+	return permissionRanks[p] >= permissionRanks[other]
+}
+
+// CanPush tells whether the permission allows pushing to the repo.
+func (p Permission) CanPush() bool {
+	return p.AtLeast(PermissionWrite)
+}
+
+// maxPermission returns the highest of the permissions.
+func maxPermission(a Permission, b Permission) Permission {
+	if a.AtLeast(b) {
+		return a
+	}
+	return b
+}
+
+// permissionOf returns the permission from the role name,
+// or from the permissions map when there is no role name
+// (e.g. custom roles, or old GitHub Enterprise versions).
+func permissionOf(roleName string, permissions *map[string]bool) Permission {
+	if _, ok := permissionRanks[Permission(roleName)]; ok {
+		return Permission(roleName)
+	}
+	switch roleName {
+	case "pull":
+		return PermissionRead
+	case "push":
+		return PermissionWrite
+	}
+	if permissions == nil {
+		return PermissionNone
+	}
+	perms := *permissions
+	switch {
+	case perms["admin"]:
+		return PermissionAdmin
+	case perms["maintain"]:
+		return PermissionMaintain
+	case perms["push"]:
+		return PermissionWrite
+	case perms["triage"]:
+		return PermissionTriage
+	case perms["pull"]:
+		return PermissionRead
+	}
+	return PermissionNone
+}
+
+const (
+	AffiliationOutside = "outside"
+	AffiliationDirect  = "direct"
+	AffiliationAll     = "all"
+)
+
+// Collaborator is a user with access to a repo.
+type Collaborator struct {
+	github.User
+	RoleName *string `json:"role_name,omitempty"`
+}
+
+// Permission returns the permission level of the collaborator on the repo.
+func (c *Collaborator) Permission() Permission {
+	var roleName string
+	if c.RoleName != nil {
+		roleName = *c.RoleName
+	}
+	return permissionOf(roleName, c.Permissions)
+}
+
+// ListCollaborators lists the collaborators of the repo;
+// affiliation is AffiliationOutside, AffiliationDirect or AffiliationAll (default).
+// NOTE: listing the collaborators requires push access to the repo.
+func (c *Client) ListCollaborators(owner string, repo string, affiliation string) ([]*Collaborator, error) {
+	var all []*Collaborator
+	var page []*Collaborator
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("repos/%v/%v/collaborators", owner, repo)
+			u, err := addOptions(u, &github.ListCollaboratorsOptions{
+				Affiliation: affiliation,
+				ListOptions: *opt,
+			})
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+type permissionLevelResponse struct {
+	Permission string       `json:"permission"`
+	RoleName   string       `json:"role_name"`
+	User       *github.User `json:"user"`
+}
+
+// GetPermissionLevel returns the permission level of the user on the repo.
+func (c *Client) GetPermissionLevel(owner string, repo string, user string) (Permission, error) {
+	client := c.client
+
+	u := fmt.Sprintf("repos/%v/%v/collaborators/%v/permission", owner, repo, user)
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return PermissionNone, err
+	}
+
+	var level permissionLevelResponse
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		resp, err = client.Do(ctx, req, &level)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			// TODO: catch rate limit error, and wait
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return PermissionNone, ErrNotFound
+		}
+		return PermissionNone, errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return PermissionNone, ErrNotFound
+	}
+
+	// role_name has the fine-grained roles (maintain, triage);
+	// permission has only admin, write, read, none.
+	if level.RoleName != "" {
+		return permissionOf(level.RoleName, nil), nil
+	}
+	return permissionOf(level.Permission, nil), nil
+}
+
+// TeamGrant is the permission granted by a team.
+type TeamGrant struct {
+	Team       string     `json:"team"`
+	Permission Permission `json:"permission"`
+}
+
+// RepoAccess is the access of a user to a repo.
+type RepoAccess struct {
+	// Permission is the effective permission
+	// (the highest of the direct, team and org base permissions).
+	Permission Permission `json:"permission"`
+	// Direct is the permission granted directly to the user (PermissionNone if none).
+	Direct Permission `json:"direct"`
+	// Teams are the permissions granted via the teams of the user.
+	Teams []TeamGrant `json:"teams,omitempty"`
+}
+
+// PermissionMatrix is the access of each user to each repo of an org.
+type PermissionMatrix struct {
+	Org   string   `json:"org"`
+	Repos []string `json:"repos"`
+	Users []string `json:"users"`
+	// Access is keyed by user, then by repo;
+	// users without access to a repo have no entry for it.
+	Access map[string]map[string]*RepoAccess `json:"access"`
+}
+
+// Get returns the access of the user to the repo; nil if none.
+func (m *PermissionMatrix) Get(user string, repo string) *RepoAccess {
+	return m.Access[user][repo]
+}
+
+func (m *PermissionMatrix) entry(user string, repo string) *RepoAccess {
+	if m.Access[user] == nil {
+		m.Access[user] = make(map[string]*RepoAccess)
+	}
+	access := m.Access[user][repo]
+	if access == nil {
+		access = &RepoAccess{
+			Permission: PermissionNone,
+			Direct:     PermissionNone,
+		}
+		m.Access[user][repo] = access
+	}
+	return access
+}
+
+type PermissionMatrixOpts struct {
+	IncludeForks    bool
+	IncludeArchived bool
+	// Concurrency is the number of repos (and teams) processed at the same time;
+	// values <= 1 mean one at a time.
+	Concurrency int
+}
+
+// Validate validates PermissionMatrixOpts.
+func (opts *PermissionMatrixOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	return nil
+}
+
+// BuildPermissionMatrix builds the matrix of the permissions of the users
+// on the repos of the org, telling apart the direct and the team grants.
+// NOTE: it requires admin access to the org.
+func (c *Client) BuildPermissionMatrix(org string, opts *PermissionMatrixOpts) (*PermissionMatrix, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	repos, err := c.ListReposByOrg(org)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListReposByOrg: %w", err)
+	}
+	matrix := &PermissionMatrix{
+		Org:    org,
+		Access: make(map[string]map[string]*RepoAccess),
+	}
+	for _, repo := range repos {
+		if repo.GetFork() && !opts.IncludeForks {
+			continue
+		}
+		if repo.GetArchived() && !opts.IncludeArchived {
+			continue
+		}
+		matrix.Repos = append(matrix.Repos, repo.GetName())
+	}
+	included := make(map[string]bool)
+	for _, repo := range matrix.Repos {
+		included[repo] = true
+	}
+
+	var mu sync.Mutex
+	group := NewSizedGroup(int64(concurrency))
+	for i := range matrix.Repos {
+		repo := matrix.Repos[i]
+		group.Go(func() error {
+			collaborators, err := c.ListCollaborators(org, repo, AffiliationAll)
+			if err != nil {
+				return fmt.Errorf("error while listing collaborators of %s: %w", repo, err)
+			}
+			direct, err := c.ListCollaborators(org, repo, AffiliationDirect)
+			if err != nil {
+				return fmt.Errorf("error while listing direct collaborators of %s: %w", repo, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, collaborator := range collaborators {
+				access := matrix.entry(collaborator.GetLogin(), repo)
+				access.Permission = maxPermission(access.Permission, collaborator.Permission())
+			}
+			for _, collaborator := range direct {
+				access := matrix.entry(collaborator.GetLogin(), repo)
+				access.Direct = collaborator.Permission()
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := c.ListTeams(org)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListTeams: %w", err)
+	}
+	group = NewSizedGroup(int64(concurrency))
+	for i := range teams {
+		team := teams[i]
+		group.Go(func() error {
+			teamRepos, err := c.ListTeamRepos(org, team.GetSlug())
+			if err != nil {
+				return fmt.Errorf("error while listing repos of team %s: %w", team.GetSlug(), err)
+			}
+			members, err := c.ListTeamMembers(org, team.GetSlug(), TeamRoleAll)
+			if err != nil {
+				return fmt.Errorf("error while listing members of team %s: %w", team.GetSlug(), err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, repo := range teamRepos {
+				if !included[repo.GetName()] {
+					continue
+				}
+				grant := TeamGrant{
+					Team:       team.GetSlug(),
+					Permission: repo.Permission(),
+				}
+				for _, member := range members {
+					access := matrix.entry(member.GetLogin(), repo.GetName())
+					access.Teams = append(access.Teams, grant)
+					access.Permission = maxPermission(access.Permission, grant.Permission)
+				}
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	for user, byRepo := range matrix.Access {
+		matrix.Users = append(matrix.Users, user)
+		for _, access := range byRepo {
+			sort.Slice(access.Teams, func(i, j int) bool {
+				return access.Teams[i].Team < access.Teams[j].Team
+			})
+		}
+	}
+	sort.Strings(matrix.Users)
+	return matrix, nil
+}
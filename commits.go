@@ -0,0 +1,206 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// CommitFileChange is a file changed by a commit.
+// It adds to github.CommitFile the fields that are missing there.
+type CommitFileChange struct {
+	github.CommitFile
+	// PreviousFilename is set for renamed files.
+	PreviousFilename *string `json:"previous_filename,omitempty"`
+}
+
+func (f *CommitFileChange) GetPreviousFilename() string {
+	if f == nil || f.PreviousFilename == nil {
+		return ""
+	}
+	return *f.PreviousFilename
+}
+
+// CommitDetails is a commit with all its changed files and stats.
+type CommitDetails struct {
+	github.RepositoryCommit
+	Files []*CommitFileChange `json:"files,omitempty"`
+}
+
+type GetCommitOpts struct {
+	// SkipPatches drops the patch of each file as soon as it is received,
+	// to save memory when only the stats are needed.
+	SkipPatches bool
+	// Concurrency is the number of commits fetched at the same time by GetCommits;
+	// values <= 1 mean one at a time.
+	Concurrency int
+}
+
+// GetCommit gets a commit with all its files.
+// The commit endpoint returns at most 300 files per page;
+// all the pages are fetched.
+// A nil opts means the default options.
+func (c *Client) GetCommit(owner string, repo string, sha string, opts *GetCommitOpts) (*CommitDetails, error) {
+	if opts == nil {
+		opts = &GetCommitOpts{}
+	}
+	client := c.client
+
+	opt := &github.ListOptions{PerPage: 300}
+	var details *CommitDetails
+	for {
+		u := fmt.Sprintf("repos/%v/%v/commits/%v", owner, repo, sha)
+		u, err := addOptions(u, opt)
+		if err != nil {
+			return nil, err
+		}
+		req, err := client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page *CommitDetails
+		var resp *github.Response
+		errs := RetryExponentialBackoff(5, time.Second, func() error {
+			var err error
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+
+			page = new(CommitDetails)
+			resp, err = client.Do(ctx, req, page)
+			if err != nil {
+				return fmt.Errorf("error while executing request: %w", err)
+			}
+			onResponse(resp)
+			if handleRateLimitError(err, resp) {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+				// TODO: catch rate limit error, and wait
+				return fmt.Errorf(
+					"status code is: %v (%s)",
+					resp.StatusCode,
+					resp.Status,
+				)
+			}
+			// nil on 200 and 404
+			return nil
+		})
+		if errs != nil && len(errs) > 0 {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, ErrNotFound
+			}
+			return nil, errors.New(FormatErrorArray("", errs))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+
+		if opts.SkipPatches {
+			for _, file := range page.Files {
+				file.Patch = nil
+			}
+		}
+		if details == nil {
+			details = page
+		} else {
+			details.Files = append(details.Files, page.Files...)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return details, nil
+}
+
+// GetCommits gets the commits with the provided SHAs (see GetCommit),
+// in the same order.
+func (c *Client) GetCommits(owner string, repo string, shas []string, opts *GetCommitOpts) ([]*CommitDetails, error) {
+	if opts == nil {
+		opts = &GetCommitOpts{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	commits := make([]*CommitDetails, len(shas))
+	group := NewSizedGroup(int64(concurrency))
+	for i := range shas {
+		index := i
+		group.Go(func() error {
+			commit, err := c.GetCommit(owner, repo, shas[index], opts)
+			if err != nil {
+				return fmt.Errorf("error while getting commit %s: %w", shas[index], err)
+			}
+			commits[index] = commit
+			return nil
+		})
+	}
+	err := group.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// Churn is the amount of changes.
+type Churn struct {
+	Commits   int
+	Additions int
+	Deletions int
+}
+
+// commitAuthorKey identifies the author of a commit:
+// the GitHub login, or the email if the commit is not linked to a user.
+func commitAuthorKey(commit *github.RepositoryCommit) string {
+	if login := commit.GetAuthor().GetLogin(); login != "" {
+		return login
+	}
+	return strings.ToLower(commit.GetCommit().GetAuthor().GetEmail())
+}
+
+// ChurnByAuthor sums the changes of the commits per author
+// (GitHub login, or email for commits not linked to a user).
+func ChurnByAuthor(commits []*CommitDetails) map[string]*Churn {
+	churn := make(map[string]*Churn)
+	for _, commit := range commits {
+		key := commitAuthorKey(&commit.RepositoryCommit)
+		if churn[key] == nil {
+			churn[key] = &Churn{}
+		}
+		churn[key].Commits++
+		for _, file := range commit.Files {
+			churn[key].Additions += file.GetAdditions()
+			churn[key].Deletions += file.GetDeletions()
+		}
+	}
+	return churn
+}
+
+// ChurnByPath sums the changes of the commits per file path.
+func ChurnByPath(commits []*CommitDetails) map[string]*Churn {
+	churn := make(map[string]*Churn)
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			key := file.GetFilename()
+			if churn[key] == nil {
+				churn[key] = &Churn{}
+			}
+			churn[key].Commits++
+			churn[key].Additions += file.GetAdditions()
+			churn[key].Deletions += file.GetDeletions()
+		}
+	}
+	return churn
+}
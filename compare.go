@@ -0,0 +1,254 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// Comparison is the comparison between two refs.
+type Comparison struct {
+	github.CommitsComparison
+	// Files are all the files changed between the merge base and the head
+	// (the API returns at most 300 files).
+	Files []*CommitFileChange `json:"files,omitempty"`
+}
+
+// Compare compares the head ref with the base ref (branches, tags or SHAs).
+// All the commits are returned: the pages of commits are fetched
+// beyond the 250 commits returned by a non-paginated comparison.
+func (c *Client) Compare(owner string, repo string, base string, head string) (*Comparison, error) {
+	client := c.client
+
+	opt := &github.ListOptions{PerPage: 100}
+	var comparison *Comparison
+	for {
+		u := fmt.Sprintf("repos/%v/%v/compare/%v...%v", owner, repo, escapePath(base), escapePath(head))
+		u, err := addOptions(u, opt)
+		if err != nil {
+			return nil, err
+		}
+		req, err := client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page *Comparison
+		var resp *github.Response
+		errs := RetryExponentialBackoff(5, time.Second, func() error {
+			var err error
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+
+			page = new(Comparison)
+			resp, err = client.Do(ctx, req, page)
+			if err != nil {
+				return fmt.Errorf("error while executing request: %w", err)
+			}
+			onResponse(resp)
+			if handleRateLimitError(err, resp) {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+				// TODO: catch rate limit error, and wait
+				return fmt.Errorf(
+					"status code is: %v (%s)",
+					resp.StatusCode,
+					resp.Status,
+				)
+			}
+			// nil on 200 and 404
+			return nil
+		})
+		if errs != nil && len(errs) > 0 {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, ErrNotFound
+			}
+			return nil, errors.New(FormatErrorArray("", errs))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+
+		if comparison == nil {
+			// NOTE: the files are only in the first page.
+			comparison = page
+		} else {
+			comparison.Commits = append(comparison.Commits, page.Commits...)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return comparison, nil
+}
+
+// Summary summarizes the files changed in the comparison.
+func (cmp *Comparison) Summary() *ChangeSummary {
+	return SummarizeChanges(cmp.Files)
+}
+
+// ChangeGroup is the summary of a group of changed files.
+type ChangeGroup struct {
+	Files     int
+	Additions int
+	Deletions int
+
+	Added    int
+	Modified int
+	Removed  int
+	Renamed  int
+}
+
+func (g *ChangeGroup) add(file *CommitFileChange) {
+	g.Files++
+	g.Additions += file.GetAdditions()
+	g.Deletions += file.GetDeletions()
+	switch file.GetStatus() {
+	case "added":
+		g.Added++
+	case "removed":
+		g.Removed++
+	case "renamed":
+		g.Renamed++
+	default:
+		g.Modified++
+	}
+}
+
+// ChangeSummary groups changed files by top-level directory and by language.
+type ChangeSummary struct {
+	Total ChangeGroup
+	// ByDirectory is keyed by top-level directory;
+	// the files in the root of the repo are under "/".
+	ByDirectory map[string]*ChangeGroup
+	// ByLanguage is keyed by language, as guessed by LanguageOfPath.
+	ByLanguage map[string]*ChangeGroup
+}
+
+// SummarizeChanges summarizes the changed files.
+func SummarizeChanges(files []*CommitFileChange) *ChangeSummary {
+	summary := &ChangeSummary{
+		ByDirectory: make(map[string]*ChangeGroup),
+		ByLanguage:  make(map[string]*ChangeGroup),
+	}
+	for _, file := range files {
+		summary.Total.add(file)
+
+		dir := topLevelDir(file.GetFilename())
+		if summary.ByDirectory[dir] == nil {
+			summary.ByDirectory[dir] = &ChangeGroup{}
+		}
+		summary.ByDirectory[dir].add(file)
+
+		lang := LanguageOfPath(file.GetFilename())
+		if summary.ByLanguage[lang] == nil {
+			summary.ByLanguage[lang] = &ChangeGroup{}
+		}
+		summary.ByLanguage[lang].add(file)
+	}
+	return summary
+}
+
+func topLevelDir(p string) string {
+	i := strings.Index(p, "/")
+	if i < 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+var languagesByFilename = map[string]string{
+	"Dockerfile":     "Dockerfile",
+	"Makefile":       "Makefile",
+	"CMakeLists.txt": "CMake",
+	"go.mod":         "Go",
+	"go.sum":         "Go",
+	"Gemfile":        "Ruby",
+	"Rakefile":       "Ruby",
+}
+
+var languagesByExtension = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".jsx":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".kts":    "Kotlin",
+	".scala":  "Scala",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".cxx":    "C++",
+	".hpp":    "C++",
+	".hh":     "C++",
+	".cs":     "C#",
+	".rs":     "Rust",
+	".swift":  "Swift",
+	".m":      "Objective-C",
+	".mm":     "Objective-C",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".zsh":    "Shell",
+	".ps1":    "PowerShell",
+	".pl":     "Perl",
+	".lua":    "Lua",
+	".r":      "R",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".erl":    "Erlang",
+	".hs":     "Haskell",
+	".clj":    "Clojure",
+	".sql":    "SQL",
+	".html":   "HTML",
+	".htm":    "HTML",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".sass":   "Sass",
+	".less":   "Less",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".md":     "Markdown",
+	".rst":    "reStructuredText",
+	".json":   "JSON",
+	".yml":    "YAML",
+	".yaml":   "YAML",
+	".toml":   "TOML",
+	".xml":    "XML",
+	".proto":  "Protocol Buffers",
+	".tf":     "HCL",
+	".sol":    "Solidity",
+}
+
+// LanguageOfPath guesses the language of a file from its name;
+// "Other" if unknown.
+func LanguageOfPath(p string) string {
+	base := path.Base(p)
+	if lang, ok := languagesByFilename[base]; ok {
+		return lang
+	}
+	if lang, ok := languagesByExtension[strings.ToLower(path.Ext(base))]; ok {
+		return lang
+	}
+	return "Other"
+}
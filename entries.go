@@ -0,0 +1,259 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// ContentKind is the kind of an entry of a repository.
+type ContentKind string
+
+const (
+	KindFile      ContentKind = "file"
+	KindDir       ContentKind = "dir"
+	KindSymlink   ContentKind = "symlink"
+	KindSubmodule ContentKind = "submodule"
+)
+
+func IsSymlink(v *github.RepositoryContent) bool {
+	return GetContentKind(v) == KindSymlink
+}
+func IsSubmodule(v *github.RepositoryContent) bool {
+	return GetContentKind(v) == KindSubmodule
+}
+
+// GetContentKind returns the kind of the content.
+// NOTE: in directory listings, the API reports submodules as "file"
+// (for backwards compatibility); they are recognized by their git URL,
+// which points to a tree (of the submodule repo) instead of a blob.
+func GetContentKind(v *github.RepositoryContent) ContentKind {
+	switch v.GetType() {
+	case "dir":
+		return KindDir
+	case "symlink":
+		return KindSymlink
+	case "submodule":
+		return KindSubmodule
+	}
+	if strings.Contains(v.GetGitURL(), "/git/trees/") {
+		return KindSubmodule
+	}
+	return KindFile
+}
+
+// RepoEntry is an entry of a repository, as provided by WalkEntries.
+type RepoEntry struct {
+	*github.RepositoryContent
+
+	Kind ContentKind
+
+	// SymlinkTarget is the target of a symlink, as stored in the repo.
+	// Set only if WithResolveSymlinks is enabled.
+	SymlinkTarget string
+	// ResolvedPath is the repo path the symlink points to;
+	// empty if the target is outside of the repo.
+	// Set only if WithResolveSymlinks is enabled.
+	ResolvedPath string
+
+	// Submodule is set for submodules.
+	Submodule *SubmoduleInfo
+
+	// LFS is set if the file is a Git LFS pointer.
+	// Set only if WithLFS is enabled.
+	LFS *LFSPointer
+}
+
+type SubmoduleInfo struct {
+	// URL is the git URL of the submodule repo.
+	URL string
+	// CommitSHA is the commit of the submodule repo
+	// that is referenced by the parent repo.
+	CommitSHA string
+}
+
+// WithResolveSymlinks makes WalkEntries resolve the targets of symlinks
+// (one extra request per symlink).
+func (r *RepoExplorationRequest) WithResolveSymlinks(resolve bool) *RepoExplorationRequest {
+	r.resolveSymlinks = resolve
+	return r
+}
+
+// WithLFS makes WalkEntries detect Git LFS pointer files
+// (one extra request per file small enough to be a pointer).
+func (r *RepoExplorationRequest) WithLFS(enabled bool) *RepoExplorationRequest {
+	r.lfs = enabled
+	return r
+}
+
+// WalkEntries is like WalkFiles, but provides the kind of each entry,
+// the info about submodules and (optionally) symlink targets and LFS pointers.
+func (r *RepoExplorationRequest) WalkEntries(walker func(e *RepoEntry) error) error {
+	return r.WalkFiles(func(v *github.RepositoryContent) error {
+		entry, err := r.newRepoEntry(v)
+		if err != nil {
+			return fmt.Errorf("error while inspecting %q: %w", v.GetPath(), err)
+		}
+		return walker(entry)
+	})
+}
+
+func (r *RepoExplorationRequest) newRepoEntry(v *github.RepositoryContent) (*RepoEntry, error) {
+	entry := &RepoEntry{
+		RepositoryContent: v,
+		Kind:              GetContentKind(v),
+	}
+
+	switch entry.Kind {
+	case KindSubmodule:
+		info, err := r.GetSubmoduleInfo(v.GetPath())
+		if err != nil {
+			return nil, err
+		}
+		entry.Submodule = info
+	case KindSymlink:
+		if !r.resolveSymlinks {
+			break
+		}
+		target, err := r.readBlob(v.GetSHA())
+		if err != nil {
+			return nil, err
+		}
+		entry.SymlinkTarget = string(target)
+		entry.ResolvedPath = resolveSymlinkTarget(v.GetPath(), entry.SymlinkTarget)
+	case KindFile:
+		if !r.lfs || v.GetSize() > maxLFSPointerSize {
+			break
+		}
+		content, err := r.readBlob(v.GetSHA())
+		if err != nil {
+			return nil, err
+		}
+		entry.LFS = ParseLFSPointer(content)
+	}
+
+	return entry, nil
+}
+
+func (r *RepoExplorationRequest) readBlob(sha string) ([]byte, error) {
+	rc, err := r.DownloadBlob(sha)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// resolveSymlinkTarget returns the repo path of the target
+// of the symlink; empty if it points outside of the repo.
+func resolveSymlinkTarget(symlinkPath string, target string) string {
+	if path.IsAbs(target) {
+		return ""
+	}
+	resolved := path.Join(path.Dir(symlinkPath), target)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return ""
+	}
+	return resolved
+}
+
+// DownloadEntry downloads the content of a file entry;
+// for Git LFS pointers, the actual LFS object is downloaded.
+func (r *RepoExplorationRequest) DownloadEntry(e *RepoEntry) (io.ReadCloser, error) {
+	if e.Kind != KindFile {
+		return nil, fmt.Errorf("cannot download %s %q", e.Kind, e.GetPath())
+	}
+	if e.LFS != nil {
+		return r.DownloadLFSObject(e.LFS)
+	}
+	return r.DownloadContent(e.RepositoryContent)
+}
+
+type submoduleContent struct {
+	github.RepositoryContent
+	SubmoduleGitURL string `json:"submodule_git_url,omitempty"`
+}
+
+// GetSubmoduleInfo returns the URL and the commit SHA of the submodule at the path.
+func (r *RepoExplorationRequest) GetSubmoduleInfo(submodulePath string) (*SubmoduleInfo, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	client := r.client.client
+
+	u := fmt.Sprintf("repos/%s/%s/contents/%s", r.params.owner, r.params.repo, escapePath(submodulePath))
+	u, err = addOptions(u, r.contentGetOptions())
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var content submoduleContent
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		resp, err = client.Do(ctx, req, &content)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			// TODO: catch rate limit error, and wait
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if content.GetType() != "submodule" {
+		return nil, fmt.Errorf("%q is not a submodule, but a %s", submodulePath, content.GetType())
+	}
+
+	return &SubmoduleInfo{
+		URL:       content.SubmoduleGitURL,
+		CommitSHA: content.GetSHA(),
+	}, nil
+}
+
+// escapePath escapes each element of a repo path.
+func escapePath(p string) string {
+	elements := strings.Split(p, "/")
+	for i := range elements {
+		elements[i] = url.PathEscape(elements[i])
+	}
+	return strings.Join(elements, "/")
+}
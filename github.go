@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -22,6 +23,16 @@ import (
 
 type Client struct {
 	client *github.Client
+
+	// rateGate is shared by all the concurrent
+	// operations run with this Client.
+	rateGate rateLimitGate
+
+	blobCache *BlobCache
+
+	// emailLogins caches the resolutions of emails to logins.
+	emailLoginsMu sync.Mutex
+	emailLogins   map[string]string
 }
 
 func NewClient(token string) *Client {
@@ -73,6 +84,98 @@ func handleRateLimitError(err error, resp *github.Response) bool {
 	}
 	return false
 }
+
+// rateLimitGate makes concurrent workers wait together
+// for the reset of the rate limit, instead of each one of them
+// hitting the rate limit on its own.
+type rateLimitGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait blocks until the rate limit (if any was hit) is reset.
+func (g *rateLimitGate) wait() {
+	g.waitContext(context.Background())
+}
+
+// waitContext is like wait, but returns the error of the context
+// if it is done before the reset.
+func (g *rateLimitGate) waitContext(ctx context.Context) error {
+	g.mu.Lock()
+	until := g.until
+	g.mu.Unlock()
+
+	d := time.Until(until)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// update closes the gate until the next reset if the rate limit
+// has been hit, or if there are no more requests remaining.
+func (g *rateLimitGate) update(err error, resp *github.Response) {
+	var reset time.Time
+	if rlErr, ok := err.(*github.RateLimitError); ok {
+		reset = rlErr.Rate.Reset.Time
+	} else if resp != nil && resp.Rate.Limit > 0 && resp.Rate.Remaining == 0 {
+		reset = resp.Rate.Reset.Time
+	}
+	if reset.IsZero() {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if reset.After(g.until) {
+		g.until = reset
+	}
+}
+
+// waitForRateLimit blocks until at least minRemaining requests
+// of the core rate limit are available.
+// Checking the rate limit does not count against it.
+func (c *Client) waitForRateLimit(minRemaining int) error {
+	c.rateGate.wait()
+
+	var limits *github.RateLimits
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		limits, resp, err = c.client.RateLimits(ctx)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		return errors.New(FormatErrorArray("", errs))
+	}
+
+	core := limits.GetCore()
+	if core != nil && core.Remaining < minRemaining {
+		c.rateGate.mu.Lock()
+		if core.Reset.Time.After(c.rateGate.until) {
+			c.rateGate.until = core.Reset.Time
+		}
+		c.rateGate.mu.Unlock()
+		c.rateGate.wait()
+	}
+	return nil
+}
+
 func IsDir(v *github.RepositoryContent) bool {
 	return v.GetType() == "dir"
 }
@@ -211,16 +314,81 @@ func addOptions(s string, opt interface{}) (string, error) {
 	return u.String(), nil
 }
 
+// listAllPages executes the request of each page (with retries)
+// until the last page; collect is called once per page,
+// after the page was received successfully.
+// A request that hits the rate limit is retried after the reset.
+func (c *Client) listAllPages(
+	fetch func(ctx context.Context, opt *github.ListOptions) (*github.Response, error),
+	collect func(),
+) error {
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+
+		var resp *github.Response
+		errs := RetryExponentialBackoff(5, time.Second, func() error {
+			var err error
+
+			c.rateGate.wait()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+
+			resp, err = fetch(ctx, opt)
+			c.rateGate.update(err, resp)
+			if err != nil {
+				return fmt.Errorf("error while executing request: %w", err)
+			}
+			onResponse(resp)
+			if handleRateLimitError(err, resp) {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+				// TODO: catch rate limit error, and wait
+				return fmt.Errorf(
+					"status code is: %v (%s)",
+					resp.StatusCode,
+					resp.Status,
+				)
+			}
+			// nil on 200 and 404
+			return nil
+		})
+		if errs != nil && len(errs) > 0 {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return ErrNotFound
+			}
+			return errors.New(FormatErrorArray("", errs))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+
+		collect()
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
 func (c *Client) GetPull(owner string, repo string, number int) (*github.PullRequest, error) {
 	var pull *github.PullRequest
 	var resp *github.Response
 	errs := RetryExponentialBackoff(5, time.Second, func() error {
 		var err error
 
+		c.rateGate.wait()
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 		defer cancel()
 
 		pull, resp, err = c.client.PullRequests.Get(ctx, owner, repo, number)
+		c.rateGate.update(err, resp)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// not an error to retry.
+			onResponse(resp)
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("error while executing request: %w", err)
 		}
@@ -256,58 +424,10 @@ func (c *Client) GetPull(owner string, repo string, number int) (*github.PullReq
 	return pull, nil
 }
 
+// ListPulls lists all the closed pull requests of a repo;
+// see ListPullRequests for the other options.
 func (c *Client) ListPulls(owner string, repo string) ([]*github.PullRequest, error) {
-	client := c.client
-
-	opt := &github.ListOptions{PerPage: 100}
-	// get all pages of results
-	var allPRs []*github.PullRequest
-	for {
-
-		var tmpPRs []*github.PullRequest
-		var resp *github.Response
-		errs := RetryExponentialBackoff(5, time.Second, func() error {
-			var err error
-
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-			defer cancel()
-
-			tmpPRs, resp, err = client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{State: "closed", ListOptions: *opt})
-			if err != nil {
-				return fmt.Errorf("error while executing request: %w", err)
-			}
-			onResponse(resp)
-			if handleRateLimitError(err, resp) {
-				return err
-			}
-
-			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
-				// TODO: catch rate limit error, and wait
-				return fmt.Errorf(
-					"status code is: %v (%s)",
-					resp.StatusCode,
-					resp.Status,
-				)
-			}
-			// nil on 200 and 404
-			return nil
-		})
-		if errs != nil && len(errs) > 0 {
-			return nil, errors.New(FormatErrorArray("", errs))
-		}
-		if resp.StatusCode == http.StatusNotFound {
-			// TODO: catch rate limit error, and wait
-			return nil, ErrNotFound
-		}
-
-		allPRs = append(allPRs, tmpPRs...)
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.Page = resp.NextPage
-	}
-
-	return allPRs, nil
+	return c.ListPullRequests(owner, repo, &ListPullsOpts{State: PullStateClosed})
 }
 
 func (c *Client) GetOrg(org string) (*github.Organization, error) {
@@ -434,74 +554,24 @@ func (c *Client) GetRepo(owner, repo string) (*github.Repository, error) {
 
 ///
 
+// ListOfficialMembers lists all the members of the org;
+// see ListOrgMembers for the filters.
 func (c *Client) ListOfficialMembers(org string) ([]*github.User, error) {
-	client := c.client
-
-	opt := &github.ListOptions{PerPage: 100}
-	// get all pages of results
-	var allUsers []*github.User
-	for {
-		//org.PublicMembersURL
-		u := fmt.Sprintf("orgs/%v/members", org)
-		u, err := addOptions(u, opt)
-		if err != nil {
-			return nil, err
-		}
-		req, err := client.NewRequest("GET", u, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var members []*github.User
-		var resp *github.Response
-		errs := RetryExponentialBackoff(5, time.Second, func() error {
-			var err error
-
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-			defer cancel()
-
-			resp, err = client.Do(ctx, req, &members)
-			if err != nil {
-				return fmt.Errorf("error while executing request: %w", err)
-			}
-			onResponse(resp)
-			if handleRateLimitError(err, resp) {
-				return err
-			}
-
-			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
-				// TODO: catch rate limit error, and wait
-				return fmt.Errorf(
-					"status code is: %v (%s)",
-					resp.StatusCode,
-					resp.Status,
-				)
-			}
-			// nil on 200 and 404
-			return nil
-		})
-		if errs != nil && len(errs) > 0 {
-			return nil, errors.New(FormatErrorArray("", errs))
-		}
-		if resp.StatusCode == http.StatusNotFound {
-			// TODO: catch rate limit error, and wait
-			return nil, ErrNotFound
-		}
-
-		allUsers = append(allUsers, members...)
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.Page = resp.NextPage
-	}
-
-	return allUsers, nil
+	return c.ListOrgMembers(org, &ListOrgMembersOpts{})
 }
 
 ///
 type RepoExplorationRequest struct {
 	params Params
 
+	// concurrency is the number of directories that are listed
+	// at the same time by WalkFiles; values <= 1 mean a sequential walk.
+	concurrency int
+	order       WalkOrder
+
+	resolveSymlinks bool
+	lfs             bool
+
 	client *Client
 }
 
@@ -513,6 +583,8 @@ func (a RepoExplorationRequest) Validate() error {
 
 type Params struct {
 	owner, repo, path string
+	// ref is the branch, tag or commit SHA; empty means the default branch.
+	ref string
 }
 
 func (a Params) Validate() error {
@@ -540,6 +612,19 @@ func (r *RepoExplorationRequest) WithStartPath(path string) *RepoExplorationRequ
 	r.params.path = path
 	return r
 }
+func (r *RepoExplorationRequest) WithRef(ref string) *RepoExplorationRequest {
+	r.params.ref = ref
+	return r
+}
+
+func (r *RepoExplorationRequest) contentGetOptions() *github.RepositoryContentGetOptions {
+	if r.params.ref == "" {
+		return nil
+	}
+	return &github.RepositoryContentGetOptions{
+		Ref: r.params.ref,
+	}
+}
 
 func (r *RepoExplorationRequest) DownloadFile(filepath string) (io.ReadCloser, error) {
 	err := r.Validate()
@@ -548,7 +633,11 @@ func (r *RepoExplorationRequest) DownloadFile(filepath string) (io.ReadCloser, e
 	}
 
 	r.params.path = filepath
-	return r.client.client.Repositories.DownloadContents(context.Background(), r.params.owner, r.params.repo, r.params.path, nil)
+	if r.client.blobCache != nil {
+		// find the SHA of the file, to look it up in the cache:
+		return r.downloadFileViaBlob(filepath)
+	}
+	return r.client.client.Repositories.DownloadContents(context.Background(), r.params.owner, r.params.repo, r.params.path, r.contentGetOptions())
 }
 
 func (r *RepoExplorationRequest) ListContents(path string) (fileContent *github.RepositoryContent, directoryContent []*github.RepositoryContent, resp *github.Response, err error) {
@@ -558,22 +647,43 @@ func (r *RepoExplorationRequest) ListContents(path string) (fileContent *github.
 	}
 
 	r.params.path = path
-	return r.client.client.Repositories.GetContents(context.Background(), r.params.owner, r.params.repo, r.params.path, nil)
+	return r.client.client.Repositories.GetContents(context.Background(), r.params.owner, r.params.repo, r.params.path, r.contentGetOptions())
 }
 
 func (r *RepoExplorationRequest) DownloadContent(v *github.RepositoryContent) (io.ReadCloser, error) {
-	owner, repo, path := extractOwnerRepoPath(v)
+	owner, repo, path, err := extractOwnerRepoPath(v)
+	if err != nil {
+		return nil, err
+	}
+	if v.GetType() == "file" && v.GetSHA() != "" {
+		// Use the blobs API, which verifies the downloaded content.
+		return r.WithOwner(owner).WithRepo(repo).DownloadBlob(v.GetSHA())
+	}
 	return r.WithOwner(owner).WithRepo(repo).DownloadFile(path)
 }
 
-func extractOwnerRepoPath(v *github.RepositoryContent) (owner, repo, path string) {
+// ContentURLError is returned when the owner and repo
+// can't be extracted from the HTMLURL of a RepositoryContent.
+type ContentURLError struct {
+	URL    string
+	Reason string
+}
+
+func (e *ContentURLError) Error() string {
+	return fmt.Sprintf("invalid content URL %q: %s", e.URL, e.Reason)
+}
+
+func extractOwnerRepoPath(v *github.RepositoryContent) (owner, repo, path string, err error) {
 	rawurl := v.GetHTMLURL()
 	htmlURL, err := url.Parse(rawurl)
 	if err != nil {
-		panic(err)
+		return "", "", "", &ContentURLError{URL: rawurl, Reason: err.Error()}
 	}
 
 	pathElements := strings.Split(htmlURL.Path, "/")
+	if len(pathElements) < 3 || pathElements[1] == "" || pathElements[2] == "" {
+		return "", "", "", &ContentURLError{URL: rawurl, Reason: "owner and repo not found in path"}
+	}
 
 	owner = pathElements[1]
 	repo = pathElements[2]
@@ -587,6 +697,9 @@ func (r *RepoExplorationRequest) WalkFiles(walker func(v *github.RepositoryConte
 	if err != nil {
 		return err
 	}
+	if r.concurrency > 1 {
+		return r.walkFilesParallel(walker)
+	}
 
 	// get initial contents
 	_,
@@ -596,6 +709,7 @@ func (r *RepoExplorationRequest) WalkFiles(walker func(v *github.RepositoryConte
 		NewRepoExplorationRequest().
 		WithOwner(r.params.owner).
 		WithRepo(r.params.repo).
+		WithRef(r.params.ref).
 		ListContents(r.params.path)
 	if err != nil {
 		panic(err)
@@ -618,6 +732,7 @@ func (r *RepoExplorationRequest) walkFiles(content []*github.RepositoryContent,
 				NewRepoExplorationRequest().
 				WithOwner(r.params.owner).
 				WithRepo(r.params.repo).
+				WithRef(r.params.ref).
 				ListContents(v.GetPath())
 			if err != nil {
 				return err
@@ -713,12 +828,14 @@ func (c *Client) ListContributors(
 		errs := RetryExponentialBackoff(5, time.Second, func() error {
 			var err error
 
+			c.rateGate.wait()
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 			defer cancel()
 
 			contributors, resp, err = client.Repositories.ListContributors(ctx, owner, repo, &github.ListContributorsOptions{
 				ListOptions: *opt,
 			})
+			c.rateGate.update(err, resp)
 			if err != nil {
 				return fmt.Errorf("error while executing request: %w", err)
 			}
@@ -765,10 +882,11 @@ func (c *Client) ListCommitsByAuthor(
 	return c.ListCommits(
 		owner,
 		repo,
-		&github.CommitsListOptions{
-			Author: author,
+		&ListCommitsOpts{
+			Author:    author,
+			Since:     sinceMaxAge(maxAge),
+			DateField: AuthorDate,
 		},
-		maxAge,
 	)
 }
 func (c *Client) ListCommitsByPath(
@@ -780,21 +898,115 @@ func (c *Client) ListCommitsByPath(
 	return c.ListCommits(
 		owner,
 		repo,
-		&github.CommitsListOptions{
-			Path: path,
+		&ListCommitsOpts{
+			Path:      path,
+			Since:     sinceMaxAge(maxAge),
+			DateField: AuthorDate,
 		},
-		maxAge,
 	)
 }
 
+// sinceMaxAge returns the start of the window of the provided max age;
+// zero time (no window) if maxAge is zero.
+func sinceMaxAge(maxAge time.Duration) time.Time {
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-maxAge)
+}
+
+// CommitDateField is the date of a commit that a time window refers to.
+type CommitDateField int
+
+const (
+	// CommitterDate is the date the commit was applied;
+	// it is the date the API filters on.
+	CommitterDate CommitDateField = iota
+	// AuthorDate is the date the commit was originally authored.
+	AuthorDate
+)
+
+type ListCommitsOpts struct {
+	// SHA is the branch name or commit SHA to start listing from;
+	// empty means the default branch.
+	SHA string
+	// Path, if set, restricts the commits to the ones that touched it.
+	Path string
+	// Author is a GitHub login or email address.
+	Author string
+
+	// Since and Until are the (inclusive) time window;
+	// a zero value means no bound.
+	Since time.Time
+	Until time.Time
+	// DateField is the date Since and Until refer to.
+	DateField CommitDateField
+
+	Limit int
+
+	// OnAttributed, if set, is called in order with the attribution
+	// of every listed commit: its parsed trailers, co-authors and sign-offs.
+	// Unless ResolveLogins is set, only the logins that don't need
+	// any request are resolved (see AttributeCommit).
+	OnAttributed  func(commit *AttributedCommit)
+	ResolveLogins bool
+}
+
+// Validate validates ListCommitsOpts.
+func (opts *ListCommitsOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && opts.Until.Before(opts.Since) {
+		return errors.New("opts.Until is before opts.Since.")
+	}
+	return nil
+}
+
+// isInWindow tells whether the commit is in the time window of the opts.
+func (opts *ListCommitsOpts) isInWindow(commit *github.RepositoryCommit) bool {
+	date := commitDate(commit, opts.DateField)
+	if !opts.Since.IsZero() && date.Before(opts.Since) {
+		return false
+	}
+	if !opts.Until.IsZero() && date.After(opts.Until) {
+		return false
+	}
+	return true
+}
+
+func commitDate(commit *github.RepositoryCommit, field CommitDateField) time.Time {
+	if field == AuthorDate {
+		return commit.GetCommit().GetAuthor().GetDate()
+	}
+	return commit.GetCommit().GetCommitter().GetDate()
+}
+
+// ListCommits lists the commits of a repo.
+// The time window is pushed to the API, which filters on the committer date;
+// when the window refers to the author date, Until is applied only client-side
+// (a commit can be committed after it was authored), and Since is pushed
+// to the API as well (a commit is usually not committed before it was authored).
 func (c *Client) ListCommits(
 	owner string,
 	repo string,
-	options *github.CommitsListOptions,
-	maxAge time.Duration,
+	opts *ListCommitsOpts,
 ) ([]*github.RepositoryCommit, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 	client := c.client
 
+	options := &github.CommitsListOptions{
+		SHA:    opts.SHA,
+		Path:   opts.Path,
+		Author: opts.Author,
+		Since:  opts.Since,
+	}
+	if opts.DateField == CommitterDate {
+		options.Until = opts.Until
+	}
+
 	opt := &github.ListOptions{PerPage: 100}
 	// get all pages of results
 	var allCommits []*github.RepositoryCommit
@@ -806,11 +1018,13 @@ PageLister:
 		errs := RetryExponentialBackoff(5, time.Second, func() error {
 			var err error
 
+			c.rateGate.wait()
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 			defer cancel()
 
 			options.ListOptions = *opt
 			commits, resp, err = client.Repositories.ListCommits(ctx, owner, repo, options)
+			c.rateGate.update(err, resp)
 			if err != nil {
 				return fmt.Errorf("error while executing request: %w", err)
 			}
@@ -838,17 +1052,21 @@ PageLister:
 			return nil, ErrNotFound
 		}
 
-		if maxAge > 0 {
-			for _, commit := range commits {
-				isTooOld := time.Now().Sub(commit.Commit.Author.GetDate()) > maxAge
-				if !isTooOld {
-					allCommits = append(allCommits, commit)
-				} else {
-					break PageLister
+		for _, commit := range commits {
+			if !opts.isInWindow(commit) {
+				continue
+			}
+			allCommits = append(allCommits, commit)
+			if opts.OnAttributed != nil {
+				attributed, err := c.AttributeCommit(commit, opts.ResolveLogins)
+				if err != nil {
+					return nil, err
 				}
+				opts.OnAttributed(attributed)
+			}
+			if opts.Limit > 0 && len(allCommits) >= opts.Limit {
+				break PageLister
 			}
-		} else {
-			allCommits = append(allCommits, commits...)
 		}
 		if resp.NextPage == 0 {
 			break PageLister
@@ -866,11 +1084,32 @@ func (c *Client) FindShadowMembersByContributions(
 	repo string,
 	maxAge time.Duration,
 ) ([]*github.Contributor, error) {
+	return c.FindShadowMembersByContributionsWithOpts(owner, repo, &ShadowMemberOpts{MaxAge: maxAge})
+}
+
+// FindShadowMembersByContributionsWithOpts is like FindShadowMembersByContributions,
+// with the choice of the algorithm.
+func (c *Client) FindShadowMembersByContributionsWithOpts(
+	owner string,
+	repo string,
+	opts *ShadowMemberOpts,
+) ([]*github.Contributor, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 
 	contributors, err := c.ListContributors(owner, repo)
 	if err != nil {
 		return nil, fmt.Errorf("error while ListContributors: %w", err)
 	}
+	if len(contributors) == 0 {
+		// e.g. an empty repo, of which the commits can't be listed (409).
+		return nil, nil
+	}
+	commitsByAuthor, err := c.newAuthorCommitsFunc(owner, repo, opts.MaxAge, opts.Algorithm)
+	if err != nil {
+		return nil, err
+	}
 
 	var shadowMembers []*github.Contributor
 	for _, contributor := range contributors {
@@ -878,7 +1117,7 @@ func (c *Client) FindShadowMembersByContributions(
 			return shadowMembers, nil
 		}
 		login := contributor.GetLogin()
-		commits, err := c.ListCommitsByAuthor(owner, repo, login, maxAge)
+		commits, err := commitsByAuthor(login)
 		if err != nil {
 			return nil, fmt.Errorf("error while ListCommitsByAuthor for %s: %s", login, err)
 		}
@@ -908,16 +1147,22 @@ func isShadowMember(commits []*github.RepositoryCommit) bool {
 	return false
 }
 
+// isDirectCommit tells whether the commit was committed by its own author.
+// An author or committer without a linked user gets the login of their
+// noreply address, if any; two unknown users (e.g. unlinked emails)
+// are not the same user; logins are compared case-insensitively, like on GitHub.
 func isDirectCommit(commit *github.RepositoryCommit) bool {
-	return commit.Author.GetLogin() == commit.Committer.GetLogin()
+	author, committer := commitLogins(commit)
+	return author != "" && strings.EqualFold(author, committer)
 }
 func isMergedByCommitterCommit(commit *github.RepositoryCommit) bool {
 	// NOTE: isMergedByCommitter is not completely reliable because
 	// I'm still not sure how to figure this out in a precise way.
-	return commit.Committer.GetLogin() == "web-flow"
+	// For the precise merge method of a pull request, see DetectMergeMethod.
+	return commit.Committer.GetLogin() == webFlowLogin
 }
 func isModeratedPRCommit(commit *github.RepositoryCommit) bool {
-	return commit.Author.GetLogin() != commit.Committer.GetLogin()
+	return !isDirectCommit(commit)
 }
 func (c *Client) IsOwnerAnOrg(owner string) (*github.Organization, bool, error) {
 	org, err := c.GetOrg(owner)
@@ -1,6 +1,6 @@
 module github.com/gagliardetto/gh-client
 
-go 1.15
+go 1.16
 
 require (
 	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef // indirect
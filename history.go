@@ -0,0 +1,326 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// FileRevision is a revision of a file, as returned by FileHistory.
+type FileRevision struct {
+	Commit *github.RepositoryCommit
+
+	// Path is the path of the file at this revision.
+	Path string
+	// PreviousPath is the path of the file before this revision (for renames).
+	PreviousPath string
+	// SHA is the blob SHA of the content of the file at this revision.
+	SHA string
+	// Status is one of "added", "modified", "renamed", "removed".
+	Status    string
+	Additions int
+	Deletions int
+	// Patch is the diff hunk of the file in this revision;
+	// empty for binary or too large diffs.
+	Patch string
+}
+
+// FileHistory returns the revisions of the file at the path,
+// starting from the ref (empty means the default branch), newest first.
+// Unlike ListCommitsByPath, renames are followed.
+// NOTE: each revision costs one extra request.
+func (c *Client) FileHistory(owner string, repo string, path string, ref string) ([]*FileRevision, error) {
+	var revisions []*FileRevision
+
+	currentPath := path
+	startRef := ref
+Lister:
+	for {
+		commits, err := c.ListCommits(
+			owner,
+			repo,
+			&ListCommitsOpts{
+				SHA:  startRef,
+				Path: currentPath,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while ListCommits for %q: %w", currentPath, err)
+		}
+
+		for _, commit := range commits {
+			details, err := c.GetCommit(owner, repo, commit.GetSHA(), nil)
+			if err != nil {
+				return nil, fmt.Errorf("error while getting commit %s: %w", commit.GetSHA(), err)
+			}
+			var file *CommitFileChange
+			for _, f := range details.Files {
+				if f.GetFilename() == currentPath {
+					file = f
+					break
+				}
+			}
+			if file == nil {
+				// e.g. a merge commit, whose diff (against the first parent)
+				// does not contain the file.
+				continue
+			}
+
+			revisions = append(revisions, &FileRevision{
+				Commit:       commit,
+				Path:         currentPath,
+				PreviousPath: file.GetPreviousFilename(),
+				SHA:          file.GetSHA(),
+				Status:       file.GetStatus(),
+				Additions:    file.GetAdditions(),
+				Deletions:    file.GetDeletions(),
+				Patch:        file.GetPatch(),
+			})
+
+			switch file.GetStatus() {
+			case "added":
+				break Lister
+			case "renamed":
+				if file.GetPreviousFilename() == "" || len(commit.Parents) == 0 {
+					break Lister
+				}
+				// continue with the history of the old path:
+				currentPath = file.GetPreviousFilename()
+				startRef = commit.Parents[0].GetSHA()
+				continue Lister
+			}
+		}
+		break
+	}
+
+	return revisions, nil
+}
+
+// BlameRange is a range of lines of a file that were last changed by the same commit.
+type BlameRange struct {
+	StartingLine int
+	EndingLine   int
+	// Age identifies the recency of the change, from 1 (new) to 10 (old).
+	Age int
+
+	CommitSHA     string
+	CommitURL     string
+	Message       string
+	AuthoredDate  time.Time
+	CommittedDate time.Time
+
+	AuthorName  string
+	AuthorEmail string
+	// AuthorLogin is empty if the author is not linked to a GitHub user.
+	AuthorLogin string
+}
+
+// FileBlame is the blame of a file.
+type FileBlame struct {
+	Path   string
+	Ref    string
+	Ranges []*BlameRange
+}
+
+// AtLine returns the range that contains the line (1-based); nil if not found.
+func (b *FileBlame) AtLine(line int) *BlameRange {
+	for _, rng := range b.Ranges {
+		if line >= rng.StartingLine && line <= rng.EndingLine {
+			return rng
+		}
+	}
+	return nil
+}
+
+const blameQuery = `query($owner: String!, $repo: String!, $ref: String!, $path: String!) {
+  repository(owner: $owner, name: $repo) {
+    object(expression: $ref) {
+      ... on Commit {
+        blame(path: $path) {
+          ranges {
+            startingLine
+            endingLine
+            age
+            commit {
+              oid
+              url
+              message
+              authoredDate
+              committedDate
+              author {
+                name
+                email
+                user {
+                  login
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type blameResponse struct {
+	Repository *struct {
+		Object *struct {
+			Blame *struct {
+				Ranges []struct {
+					StartingLine int `json:"startingLine"`
+					EndingLine   int `json:"endingLine"`
+					Age          int `json:"age"`
+					Commit       struct {
+						OID           string    `json:"oid"`
+						URL           string    `json:"url"`
+						Message       string    `json:"message"`
+						AuthoredDate  time.Time `json:"authoredDate"`
+						CommittedDate time.Time `json:"committedDate"`
+						Author        struct {
+							Name  string `json:"name"`
+							Email string `json:"email"`
+							User  *struct {
+								Login string `json:"login"`
+							} `json:"user"`
+						} `json:"author"`
+					} `json:"commit"`
+				} `json:"ranges"`
+			} `json:"blame"`
+		} `json:"object"`
+	} `json:"repository"`
+}
+
+// Blame returns the blame of the file at the path,
+// at the ref (empty means the default branch).
+// It uses the GraphQL API.
+func (c *Client) Blame(owner string, repo string, path string, ref string) (*FileBlame, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	var data blameResponse
+	err := c.graphQL(
+		blameQuery,
+		map[string]interface{}{
+			"owner": owner,
+			"repo":  repo,
+			"ref":   ref,
+			"path":  path,
+		},
+		&data,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if data.Repository == nil || data.Repository.Object == nil || data.Repository.Object.Blame == nil {
+		return nil, ErrNotFound
+	}
+
+	blame := &FileBlame{
+		Path: path,
+		Ref:  ref,
+	}
+	for _, rng := range data.Repository.Object.Blame.Ranges {
+		br := &BlameRange{
+			StartingLine:  rng.StartingLine,
+			EndingLine:    rng.EndingLine,
+			Age:           rng.Age,
+			CommitSHA:     rng.Commit.OID,
+			CommitURL:     rng.Commit.URL,
+			Message:       rng.Commit.Message,
+			AuthoredDate:  rng.Commit.AuthoredDate,
+			CommittedDate: rng.Commit.CommittedDate,
+			AuthorName:    rng.Commit.Author.Name,
+			AuthorEmail:   rng.Commit.Author.Email,
+		}
+		if rng.Commit.Author.User != nil {
+			br.AuthorLogin = rng.Commit.Author.User.Login
+		}
+		blame.Ranges = append(blame.Ranges, br)
+	}
+	return blame, nil
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type graphQLResponse struct {
+	Data   interface{}    `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// graphQLEndpoint returns the URL of the GraphQL API, relative to the BaseURL;
+// on GitHub Enterprise the GraphQL API is at /api/graphql instead of /api/v3/graphql.
+func (c *Client) graphQLEndpoint() string {
+	if strings.HasSuffix(c.client.BaseURL.Path, "/v3/") {
+		return "../graphql"
+	}
+	return "graphql"
+}
+
+// graphQL executes the GraphQL query, and decodes the data into v.
+func (c *Client) graphQL(query string, variables map[string]interface{}, v interface{}) error {
+	client := c.client
+
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		// the body of the request must be re-created at each attempt.
+		req, err := client.NewRequest("POST", c.graphQLEndpoint(), &graphQLRequest{
+			Query:     query,
+			Variables: variables,
+		})
+		if err != nil {
+			return err
+		}
+
+		gqlResp := &graphQLResponse{Data: v}
+		resp, err = client.Do(ctx, req, gqlResp)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		if len(gqlResp.Errors) > 0 {
+			if gqlResp.Errors[0].Type == "NOT_FOUND" {
+				return nil
+			}
+			var msgs []string
+			for _, gqlErr := range gqlResp.Errors {
+				msgs = append(msgs, gqlErr.Message)
+			}
+			return fmt.Errorf("graphql errors: %s", strings.Join(msgs, "; "))
+		}
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		return errors.New(FormatErrorArray("", errs))
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// maxLFSPointerSize is the max size of a Git LFS pointer file, as per spec.
+const maxLFSPointerSize = 1024
+
+const lfsSpecVersion = "https://git-lfs.github.com/spec/v1"
+
+// LFSPointer is the content of a Git LFS pointer file.
+type LFSPointer struct {
+	// OID is the sha256 of the LFS object (without the "sha256:" prefix).
+	OID  string
+	Size int64
+}
+
+// ParseLFSPointer parses the content of a Git LFS pointer file;
+// it returns nil if the content is not a valid pointer.
+func ParseLFSPointer(content []byte) *LFSPointer {
+	if len(content) > maxLFSPointerSize {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) < 3 || lines[0] != "version "+lfsSpecVersion {
+		return nil
+	}
+
+	pointer := &LFSPointer{Size: -1}
+	for _, line := range lines[1:] {
+		key, value := splitKeyValue(line)
+		switch key {
+		case "oid":
+			if !strings.HasPrefix(value, "sha256:") {
+				return nil
+			}
+			pointer.OID = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil
+			}
+			pointer.Size = size
+		}
+	}
+	if len(pointer.OID) != 64 || pointer.Size < 0 {
+		return nil
+	}
+	return pointer
+}
+
+func splitKeyValue(line string) (string, string) {
+	i := strings.Index(line, " ")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], line[i+1:]
+}
+
+type lfsBatchRequest struct {
+	Operation string           `json:"operation"`
+	Transfers []string         `json:"transfers"`
+	Objects   []lfsBatchObject `json:"objects"`
+	Ref       *lfsBatchRefSpec `json:"ref,omitempty"`
+}
+
+type lfsBatchRefSpec struct {
+	Name string `json:"name"`
+}
+
+type lfsBatchObject struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		OID     string `json:"oid"`
+		Size    int64  `json:"size"`
+		Actions struct {
+			Download *struct {
+				Href   string            `json:"href"`
+				Header map[string]string `json:"header"`
+			} `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+// DownloadLFSObject downloads the Git LFS object the pointer refers to,
+// using the LFS batch API of the repo.
+// The content is verified while it is read: at the end, an ErrLFSSizeMismatch
+// or a *BlobHashMismatchError is returned instead of io.EOF if it does not match the pointer.
+func (r *RepoExplorationRequest) DownloadLFSObject(pointer *LFSPointer) (io.ReadCloser, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if pointer == nil {
+		return nil, errors.New("pointer not provided")
+	}
+	client := r.client.client
+
+	if r.client.blobCache != nil {
+		if cached, ok := r.client.blobCache.get(blobCacheKindLFS, pointer.OID); ok {
+			return cached, nil
+		}
+	}
+
+	batch := &lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects: []lfsBatchObject{
+			{
+				OID:  pointer.OID,
+				Size: pointer.Size,
+			},
+		},
+	}
+	if r.params.ref != "" {
+		batch.Ref = &lfsBatchRefSpec{Name: r.params.ref}
+	}
+	u := r.client.lfsBatchURL(r.params.owner, r.params.repo)
+
+	var batchResp lfsBatchResponse
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		// the body of the request must be re-created at each attempt.
+		req, err := client.NewRequest("POST", u, batch)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/vnd.git-lfs+json")
+		req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+
+		resp, err = client.Do(ctx, req, &batchResp)
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(FormatErrorArray("", errs))
+	}
+
+	for _, obj := range batchResp.Objects {
+		if obj.OID != pointer.OID {
+			continue
+		}
+		if obj.Error != nil {
+			if obj.Error.Code == http.StatusNotFound {
+				return nil, ErrNotFound
+			}
+			return nil, fmt.Errorf("LFS error for %s: %v (%s)", obj.OID, obj.Error.Code, obj.Error.Message)
+		}
+		if obj.Actions.Download == nil {
+			return nil, fmt.Errorf("no download action for LFS object %s", obj.OID)
+		}
+		body, err := downloadLFSHref(obj.Actions.Download.Href, obj.Actions.Download.Header)
+		if err != nil {
+			return nil, err
+		}
+		body = newLFSVerifyingReader(pointer, body)
+		if r.client.blobCache != nil {
+			body = r.client.blobCache.newLFSCachingReader(pointer.OID, body)
+		}
+		return body, nil
+	}
+
+	return nil, fmt.Errorf("LFS object %s not found in batch response", pointer.OID)
+}
+
+// lfsBatchURL returns the URL of the LFS batch API of the repo,
+// which is served by the web host, not by the API host:
+// https://api.github.com/ -> https://github.com/
+// https://ghe.example.com/api/v3/ -> https://ghe.example.com/
+func (c *Client) lfsBatchURL(owner string, repo string) string {
+	base := *c.client.BaseURL
+	base.Host = strings.TrimPrefix(base.Host, "api.")
+	if i := strings.Index(base.Path, "/api/v3/"); i >= 0 {
+		base.Path = base.Path[:i+1]
+	} else {
+		base.Path = "/"
+	}
+	return fmt.Sprintf("%s%s/%s.git/info/lfs/objects/batch", base.String(), owner, repo)
+}
+
+// ErrLFSSizeMismatch is returned when the size of the downloaded
+// LFS object is not the one in the pointer.
+var ErrLFSSizeMismatch = errors.New("LFS object size mismatch")
+
+// lfsVerifyingReader verifies the LFS object that is read through it
+// against the sha256 and the size in the pointer; on mismatch, it returns
+// an error instead of io.EOF.
+type lfsVerifyingReader struct {
+	rc      io.ReadCloser
+	pointer *LFSPointer
+	hash    hash.Hash
+	size    int64
+}
+
+func newLFSVerifyingReader(pointer *LFSPointer, rc io.ReadCloser) io.ReadCloser {
+	return &lfsVerifyingReader{
+		rc:      rc,
+		pointer: pointer,
+		hash:    sha256.New(),
+	}
+}
+
+func (vr *lfsVerifyingReader) Read(p []byte) (int, error) {
+	n, err := vr.rc.Read(p)
+	vr.hash.Write(p[:n])
+	vr.size += int64(n)
+	if vr.size > vr.pointer.Size {
+		return n, fmt.Errorf("%w: expected %v bytes, got more", ErrLFSSizeMismatch, vr.pointer.Size)
+	}
+	if err == io.EOF {
+		if vr.size != vr.pointer.Size {
+			return n, fmt.Errorf("%w: expected %v bytes, got %v", ErrLFSSizeMismatch, vr.pointer.Size, vr.size)
+		}
+		if actual := hex.EncodeToString(vr.hash.Sum(nil)); !strings.EqualFold(actual, vr.pointer.OID) {
+			return n, &BlobHashMismatchError{
+				Expected: vr.pointer.OID,
+				Actual:   actual,
+			}
+		}
+	}
+	return n, err
+}
+
+func (vr *lfsVerifyingReader) Close() error {
+	return vr.rc.Close()
+}
+
+// downloadLFSHref downloads from the storage URL provided by the LFS batch API;
+// the request is authenticated only with the headers provided by the API.
+func downloadLFSHref(href string, header map[string]string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", href, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while downloading LFS object: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg := new(bytes.Buffer)
+		io.Copy(msg, io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf(
+			"error while downloading LFS object: status code is: %v (%s): %s",
+			resp.StatusCode,
+			resp.Status,
+			msg.String(),
+		)
+	}
+	return resp.Body, nil
+}
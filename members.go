@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	OutsideCollaboratorsFilterAll         = "all"
+	OutsideCollaboratorsFilter2FADisabled = "2fa_disabled"
+)
+
+// ListOutsideCollaborators lists the users who are collaborators
+// of at least one repo of the org, but not members of it;
+// filter is OutsideCollaboratorsFilterAll (or empty) or OutsideCollaboratorsFilter2FADisabled.
+// NOTE: only the owners of the org can list the outside collaborators.
+func (c *Client) ListOutsideCollaborators(org string, filter string) ([]*github.User, error) {
+	var all []*github.User
+	var page []*github.User
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.Organizations.ListOutsideCollaborators(ctx, org, &github.ListOutsideCollaboratorsOptions{
+				Filter:      filter,
+				ListOptions: *opt,
+			})
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+const (
+	MemberRoleAll    = "all"
+	MemberRoleAdmin  = "admin"
+	MemberRoleMember = "member"
+
+	MemberFilterAll         = "all"
+	MemberFilter2FADisabled = "2fa_disabled"
+)
+
+type ListOrgMembersOpts struct {
+	// Role is MemberRoleAll (or empty), MemberRoleAdmin or MemberRoleMember.
+	Role string
+	// Filter is MemberFilterAll (or empty) or MemberFilter2FADisabled;
+	// only the owners of the org can use MemberFilter2FADisabled.
+	Filter string
+}
+
+// Validate validates ListOrgMembersOpts.
+func (opts *ListOrgMembersOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	switch opts.Role {
+	case "", MemberRoleAll, MemberRoleAdmin, MemberRoleMember:
+	default:
+		return fmt.Errorf("opts.Role is not valid: %q", opts.Role)
+	}
+	switch opts.Filter {
+	case "", MemberFilterAll, MemberFilter2FADisabled:
+	default:
+		return fmt.Errorf("opts.Filter is not valid: %q", opts.Filter)
+	}
+	return nil
+}
+
+// ListOrgMembers lists the members of the org.
+// The concealed members are listed only if the authenticated user is a member of the org.
+func (c *Client) ListOrgMembers(org string, opts *ListOrgMembersOpts) ([]*github.User, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	var all []*github.User
+	var page []*github.User
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.Organizations.ListMembers(ctx, org, &github.ListMembersOptions{
+				Role:        opts.Role,
+				Filter:      opts.Filter,
+				ListOptions: *opt,
+			})
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ListPendingInvitations lists the invitations to the org
+// that have not been accepted yet.
+// NOTE: only the owners of the org can list the invitations.
+func (c *Client) ListPendingInvitations(org string) ([]*github.Invitation, error) {
+	var all []*github.Invitation
+	var page []*github.Invitation
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.Organizations.ListPendingOrgInvitations(ctx, org, opt)
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// FailedInvitation is an invitation to an org that failed (e.g. it expired).
+type FailedInvitation struct {
+	github.Invitation
+	FailedAt     *time.Time `json:"failed_at,omitempty"`
+	FailedReason *string    `json:"failed_reason,omitempty"`
+}
+
+func (i *FailedInvitation) GetFailedAt() time.Time {
+	if i == nil || i.FailedAt == nil {
+		return time.Time{}
+	}
+	return *i.FailedAt
+}
+
+func (i *FailedInvitation) GetFailedReason() string {
+	if i == nil || i.FailedReason == nil {
+		return ""
+	}
+	return *i.FailedReason
+}
+
+// ListFailedInvitations lists the invitations to the org that failed.
+// NOTE: only the owners of the org can list the invitations.
+func (c *Client) ListFailedInvitations(org string) ([]*FailedInvitation, error) {
+	var all []*FailedInvitation
+	var page []*FailedInvitation
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("orgs/%v/failed_invitations", org)
+			u, err := addOptions(u, opt)
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
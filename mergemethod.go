@@ -0,0 +1,128 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+type MergeMethod string
+
+const (
+	MergeMethodUnknown MergeMethod = ""
+	// MergeMethodMerge is a merge commit.
+	MergeMethodMerge MergeMethod = "merge"
+	// MergeMethodSquash is a single commit with all the changes of the pull request.
+	MergeMethodSquash MergeMethod = "squash"
+	// MergeMethodRebase is the commits of the pull request applied on the base branch
+	// (also when they were fast-forwarded without being rewritten).
+	MergeMethodRebase MergeMethod = "rebase"
+)
+
+// ErrPullNotMerged is returned when the merge method of a pull request
+// that was not merged is requested.
+var ErrPullNotMerged = errors.New("pull request not merged")
+
+// DetectMergeMethod detects how the pull request was merged.
+func (c *Client) DetectMergeMethod(owner string, repo string, number int) (MergeMethod, error) {
+	pull, err := c.GetPull(owner, repo, number)
+	if err != nil {
+		return MergeMethodUnknown, err
+	}
+	if !pull.GetMerged() && pull.MergedAt == nil {
+		return MergeMethodUnknown, ErrPullNotMerged
+	}
+	commits, err := c.ListPullCommits(owner, repo, number)
+	if err != nil {
+		return MergeMethodUnknown, fmt.Errorf("error while listing commits: %w", err)
+	}
+	return c.detectMergeMethod(owner, repo, pull, commits)
+}
+
+// detectMergeMethod detects the merge method from the merge commit:
+// - a merge commit has more than one parent;
+// - the head of a fast-forwarded pull request is the merge commit itself;
+// - the last commits of the base branch have the same messages and authors
+// as the commits of a rebased pull request;
+// - otherwise it was squashed.
+func (c *Client) detectMergeMethod(owner string, repo string, pull *github.PullRequest, pullCommits []*github.RepositoryCommit) (MergeMethod, error) {
+	mergeSHA := pull.GetMergeCommitSHA()
+	if mergeSHA == "" {
+		return MergeMethodUnknown, nil
+	}
+	for _, commit := range pullCommits {
+		if commit.GetSHA() == mergeSHA {
+			return MergeMethodRebase, nil
+		}
+	}
+
+	// the last len(pullCommits) commits of the base branch, newest first:
+	baseCommits, err := c.ListCommits(owner, repo, &ListCommitsOpts{
+		SHA:   mergeSHA,
+		Limit: len(pullCommits) + 1,
+	})
+	if err != nil {
+		return MergeMethodUnknown, fmt.Errorf("error while listing base commits: %w", err)
+	}
+	if len(baseCommits) == 0 {
+		return MergeMethodUnknown, nil
+	}
+	if len(baseCommits[0].Parents) > 1 {
+		return MergeMethodMerge, nil
+	}
+	if len(pullCommits) == 0 || len(baseCommits) < len(pullCommits) {
+		return MergeMethodSquash, nil
+	}
+
+	for i, pullCommit := range pullCommits {
+		baseCommit := baseCommits[len(pullCommits)-1-i]
+		if !isSameChange(pullCommit, baseCommit) {
+			return MergeMethodSquash, nil
+		}
+	}
+	return MergeMethodRebase, nil
+}
+
+// isSameChange tells whether the two commits look like the same change
+// (e.g. a commit and its rebased copy).
+func isSameChange(a *github.RepositoryCommit, b *github.RepositoryCommit) bool {
+	return strings.TrimSpace(a.GetCommit().GetMessage()) == strings.TrimSpace(b.GetCommit().GetMessage()) &&
+		strings.EqualFold(a.GetCommit().GetAuthor().GetEmail(), b.GetCommit().GetAuthor().GetEmail()) &&
+		a.GetCommit().GetAuthor().GetDate().Equal(b.GetCommit().GetAuthor().GetDate())
+}
+
+var (
+	// mergePullMessageRegex matches the message of the merge commits created by GitHub.
+	mergePullMessageRegex = regexp.MustCompile(`^Merge pull request #(\d+) from `)
+	// squashPullMessageRegex matches the subject of the squashed commits created by GitHub.
+	squashPullMessageRegex = regexp.MustCompile(`\(#(\d+)\)$`)
+)
+
+// PullMergeOfCommit tells whether the commit is the result of merging a pull request
+// with the default messages of GitHub, and returns the number of the pull request
+// and the merge method (only MergeMethodMerge or MergeMethodSquash:
+// rebased commits don't have any trace of the pull request).
+// It does not need any request, but it does not recognize custom messages,
+// and a subject ending with an issue reference like "(#123)" looks like a squash:
+// the result is a hint, to be confirmed with ListPullsOfCommit or DetectMergeMethod.
+func PullMergeOfCommit(commit *github.RepositoryCommit) (int, MergeMethod, bool) {
+	message := commit.GetCommit().GetMessage()
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+
+	if len(commit.Parents) > 1 {
+		if match := mergePullMessageRegex.FindStringSubmatch(subject); match != nil {
+			number, _ := strconv.Atoi(match[1])
+			return number, MergeMethodMerge, true
+		}
+		return 0, MergeMethodUnknown, false
+	}
+	if match := squashPullMessageRegex.FindStringSubmatch(subject); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number, MergeMethodSquash, true
+	}
+	return 0, MergeMethodUnknown, false
+}
@@ -0,0 +1,316 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+)
+
+// minRemainingRequestsPerRepo is the number of requests left in the rate limit
+// below which the scan of a repo waits for the reset.
+const minRemainingRequestsPerRepo = 100
+
+type OrgShadowScanOpts struct {
+	// MaxAge is the age of the commits considered; <= 0 means all the history.
+	MaxAge time.Duration
+
+	IncludeForks    bool
+	IncludeArchived bool
+
+	// Algorithm defaults to ShadowAlgorithmPerContributor (see ShadowMemberOpts).
+	Algorithm ShadowAlgorithm
+	// ConfirmMergeMethod: see ShadowMemberOpts.
+	ConfirmMergeMethod bool
+
+	// Concurrency is the number of repos scanned at the same time;
+	// values <= 1 mean one at a time.
+	Concurrency int
+
+	// CheckpointPath, if set, is the file where the results of each scanned repo
+	// are saved; a scan with the same checkpoint resumes from it,
+	// skipping the repos already scanned. A checkpoint saved with a different
+	// MaxAge, IncludeForks, IncludeArchived or ConfirmMergeMethod is refused.
+	CheckpointPath string
+}
+
+// Validate validates OrgShadowScanOpts.
+func (opts *OrgShadowScanOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	return opts.shadowMemberOpts().Validate()
+}
+
+// shadowMemberOpts returns the options of the analysis of each repo.
+func (opts *OrgShadowScanOpts) shadowMemberOpts() *ShadowMemberOpts {
+	return &ShadowMemberOpts{
+		MaxAge:             opts.MaxAge,
+		Algorithm:          opts.Algorithm,
+		ConfirmMergeMethod: opts.ConfirmMergeMethod,
+	}
+}
+
+// OrgShadowMember is a shadow member of an org, with the evidence from all the repos.
+type OrgShadowMember struct {
+	ShadowMember
+	Repos []string `json:"repos"`
+}
+
+// OrgShadowReport is the result of the shadow-member analysis of all the repos of an org.
+type OrgShadowReport struct {
+	Org         string    `json:"org"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Repos are the scanned repos.
+	Repos []string `json:"repos"`
+	// Incomplete is set when the scan was interrupted by IsExitingFunc,
+	// or when some repos could not be scanned (see Failed);
+	// it can be resumed from the checkpoint.
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Failed are the repos that could not be scanned, with the error.
+	Failed map[string]string `json:"failed,omitempty"`
+
+	OutsideCollaboratorsError string `json:"outside_collaborators_error,omitempty"`
+
+	// Members are the shadow members found in any repo, deduplicated.
+	Members []*OrgShadowMember `json:"members"`
+	// NotOfficial are the logins of the members that are not official members of the org.
+	NotOfficial []string `json:"not_official"`
+}
+
+type orgShadowCheckpoint struct {
+	Org string `json:"org"`
+	// Opts are the options the results were computed with.
+	Opts  orgShadowCheckpointOpts    `json:"opts"`
+	Repos map[string][]*ShadowMember `json:"repos"`
+}
+
+// orgShadowCheckpointOpts are the options of OrgShadowScanOpts
+// that change the results of a scan.
+type orgShadowCheckpointOpts struct {
+	MaxAge             time.Duration `json:"max_age"`
+	IncludeForks       bool          `json:"include_forks"`
+	IncludeArchived    bool          `json:"include_archived"`
+	ConfirmMergeMethod bool          `json:"confirm_merge_method,omitempty"`
+}
+
+func loadOrgShadowCheckpoint(path string, org string, opts *OrgShadowScanOpts) (*orgShadowCheckpoint, error) {
+	checkpoint := &orgShadowCheckpoint{
+		Org: org,
+		Opts: orgShadowCheckpointOpts{
+			MaxAge:             opts.MaxAge,
+			IncludeForks:       opts.IncludeForks,
+			IncludeArchived:    opts.IncludeArchived,
+			ConfirmMergeMethod: opts.ConfirmMergeMethod,
+		},
+		Repos: make(map[string][]*ShadowMember),
+	}
+	if path == "" {
+		return checkpoint, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkpoint, nil
+		}
+		return nil, err
+	}
+	var saved orgShadowCheckpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("error while decoding checkpoint %s: %w", path, err)
+	}
+	if !strings.EqualFold(saved.Org, org) {
+		return nil, fmt.Errorf("checkpoint %s is of org %q, not %q", path, saved.Org, org)
+	}
+	if saved.Opts != checkpoint.Opts {
+		return nil, fmt.Errorf("checkpoint %s was saved with different options: %+v, not %+v", path, saved.Opts, checkpoint.Opts)
+	}
+	for repo, members := range saved.Repos {
+		checkpoint.Repos[repo] = members
+	}
+	return checkpoint, nil
+}
+
+// save atomically writes the checkpoint.
+func (cp *orgShadowCheckpoint) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// FindShadowMembersInOrg runs the shadow-member analysis on all the repos of the org
+// (see ExplainShadowMembers), and merges the results by user.
+func (c *Client) FindShadowMembersInOrg(org string, opts *OrgShadowScanOpts) (*OrgShadowReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	checkpoint, err := loadOrgShadowCheckpoint(opts.CheckpointPath, org, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := c.getShadowMembership(org)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := c.ListReposByOrg(org)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListReposByOrg: %w", err)
+	}
+	var names []string
+	for _, repo := range repos {
+		if repo.GetFork() && !opts.IncludeForks {
+			continue
+		}
+		if repo.GetArchived() && !opts.IncludeArchived {
+			continue
+		}
+		names = append(names, repo.GetName())
+	}
+
+	var mu sync.Mutex
+	var incomplete bool
+	failed := make(map[string]string)
+	group := NewSizedGroup(int64(concurrency))
+	for i := range names {
+		name := names[i]
+		mu.Lock()
+		_, done := checkpoint.Repos[name]
+		mu.Unlock()
+		if done {
+			continue
+		}
+		if IsExitingFunc != nil && IsExitingFunc() {
+			mu.Lock()
+			incomplete = true
+			mu.Unlock()
+			break
+		}
+		group.Go(func() error {
+			err := c.waitForRateLimit(minRemainingRequestsPerRepo)
+			var members []*ShadowMember
+			if err == nil {
+				members, err = c.findShadowMembersInRepo(org, name, opts.shadowMemberOpts(), membership)
+			}
+			if err != nil {
+				// a failing repo (e.g. empty, or not accessible)
+				// does not stop the scan of the others.
+				mu.Lock()
+				incomplete = true
+				if err != errInterrupted {
+					failed[name] = err.Error()
+				}
+				mu.Unlock()
+				return nil
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			checkpoint.Repos[name] = members
+			if err := checkpoint.save(opts.CheckpointPath); err != nil {
+				return fmt.Errorf("error while saving checkpoint: %w", err)
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OrgShadowReport{
+		Org:                       org,
+		GeneratedAt:               time.Now(),
+		Incomplete:                incomplete,
+		OutsideCollaboratorsError: membership.outsideErr,
+	}
+	if len(failed) > 0 {
+		report.Failed = failed
+	}
+	scanned := make(map[string][]*ShadowMember)
+	for _, name := range names {
+		if members, ok := checkpoint.Repos[name]; ok {
+			report.Repos = append(report.Repos, name)
+			scanned[name] = members
+		}
+	}
+	report.Members = mergeShadowMembers(scanned)
+	for _, member := range report.Members {
+		// the membership can have changed since the checkpoint was saved.
+		member.OfficialMember = membership.isOfficial(member.Login)
+		member.OutsideCollaborator = membership.isOutside(member.Login)
+		member.Confidence = member.confidence()
+		if !member.OfficialMember {
+			report.NotOfficial = append(report.NotOfficial, member.Login)
+		}
+	}
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		return report.Members[i].Confidence > report.Members[j].Confidence
+	})
+	return report, nil
+}
+
+// mergeShadowMembers merges the shadow members of each repo by login.
+func mergeShadowMembers(byRepo map[string][]*ShadowMember) []*OrgShadowMember {
+	var repos []string
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var merged []*OrgShadowMember
+	byLogin := make(map[string]*OrgShadowMember)
+	for _, repo := range repos {
+		for _, member := range byRepo[repo] {
+			key := strings.ToLower(member.Login)
+			orgMember := byLogin[key]
+			if orgMember == nil {
+				orgMember = &OrgShadowMember{
+					ShadowMember: ShadowMember{
+						Login: member.Login,
+					},
+				}
+				byLogin[key] = orgMember
+				merged = append(merged, orgMember)
+			}
+			orgMember.Repos = append(orgMember.Repos, repo)
+			orgMember.Contributions += member.Contributions
+			for _, evidence := range member.Evidence {
+				orgMember.addEvidence(evidence)
+			}
+		}
+	}
+	return merged
+}
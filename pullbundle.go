@@ -0,0 +1,206 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// PullBundle is a pull request with everything attached to it.
+type PullBundle struct {
+	Pull *github.PullRequest `json:"pull"`
+
+	Files   []*CommitFileChange         `json:"files"`
+	Commits []*github.RepositoryCommit  `json:"commits"`
+	Reviews []*github.PullRequestReview `json:"reviews"`
+	// ReviewComments are the comments on the diff.
+	ReviewComments []*github.PullRequestComment `json:"review_comments"`
+	// IssueComments are the comments on the conversation.
+	IssueComments []*github.IssueComment `json:"issue_comments"`
+
+	// MergeMethod is set for merged pull requests.
+	MergeMethod MergeMethod `json:"merge_method,omitempty"`
+}
+
+// GetPullBundle gets the pull request with its changed files, commits,
+// reviews, review comments and issue comments (all the pages).
+func (c *Client) GetPullBundle(owner string, repo string, number int) (*PullBundle, error) {
+	pull, err := c.GetPull(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &PullBundle{
+		Pull: pull,
+	}
+
+	group := NewSizedGroup(5)
+	group.Go(func() error {
+		files, err := c.ListPullFiles(owner, repo, number)
+		if err != nil {
+			return fmt.Errorf("error while listing files: %w", err)
+		}
+		bundle.Files = files
+		return nil
+	})
+	group.Go(func() error {
+		commits, err := c.ListPullCommits(owner, repo, number)
+		if err != nil {
+			return fmt.Errorf("error while listing commits: %w", err)
+		}
+		bundle.Commits = commits
+		return nil
+	})
+	group.Go(func() error {
+		reviews, err := c.ListPullReviews(owner, repo, number)
+		if err != nil {
+			return fmt.Errorf("error while listing reviews: %w", err)
+		}
+		bundle.Reviews = reviews
+		return nil
+	})
+	group.Go(func() error {
+		comments, err := c.ListPullReviewComments(owner, repo, number)
+		if err != nil {
+			return fmt.Errorf("error while listing review comments: %w", err)
+		}
+		bundle.ReviewComments = comments
+		return nil
+	})
+	group.Go(func() error {
+		comments, err := c.ListIssueComments(owner, repo, number)
+		if err != nil {
+			return fmt.Errorf("error while listing issue comments: %w", err)
+		}
+		bundle.IssueComments = comments
+		return nil
+	})
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	if pull.GetMerged() || pull.MergedAt != nil {
+		bundle.MergeMethod, err = c.detectMergeMethod(owner, repo, pull, bundle.Commits)
+		if err != nil {
+			return nil, fmt.Errorf("error while detecting merge method: %w", err)
+		}
+	}
+
+	return bundle, nil
+}
+
+// ListPullFiles lists the files changed by the pull request
+// (the API returns at most 3000 files).
+func (c *Client) ListPullFiles(owner string, repo string, number int) ([]*CommitFileChange, error) {
+	var all []*CommitFileChange
+	var page []*CommitFileChange
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("repos/%v/%v/pulls/%d/files", owner, repo, number)
+			u, err := addOptions(u, opt)
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ListPullCommits lists the commits of the pull request
+// (the API returns at most 250 commits).
+func (c *Client) ListPullCommits(owner string, repo string, number int) ([]*github.RepositoryCommit, error) {
+	var all []*github.RepositoryCommit
+	var page []*github.RepositoryCommit
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.PullRequests.ListCommits(ctx, owner, repo, number, opt)
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ListPullReviews lists the reviews of the pull request, oldest first.
+func (c *Client) ListPullReviews(owner string, repo string, number int) ([]*github.PullRequestReview, error) {
+	var all []*github.PullRequestReview
+	var page []*github.PullRequestReview
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.PullRequests.ListReviews(ctx, owner, repo, number, opt)
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ListPullReviewComments lists the review comments (on the diff) of the pull request.
+func (c *Client) ListPullReviewComments(owner string, repo string, number int) ([]*github.PullRequestComment, error) {
+	var all []*github.PullRequestComment
+	var page []*github.PullRequestComment
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.PullRequests.ListComments(ctx, owner, repo, number, &github.PullRequestListCommentsOptions{ListOptions: *opt})
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ListIssueComments lists the comments of the issue or pull request.
+func (c *Client) ListIssueComments(owner string, repo string, number int) ([]*github.IssueComment, error) {
+	var all []*github.IssueComment
+	var page []*github.IssueComment
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.Issues.ListComments(ctx, owner, repo, number, &github.IssueListCommentsOptions{ListOptions: *opt})
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
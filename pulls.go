@@ -0,0 +1,223 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+const (
+	PullStateOpen   = "open"
+	PullStateClosed = "closed"
+	PullStateAll    = "all"
+
+	PullSortCreated     = "created"
+	PullSortUpdated     = "updated"
+	PullSortPopularity  = "popularity"
+	PullSortLongRunning = "long-running"
+
+	DirectionAsc  = "asc"
+	DirectionDesc = "desc"
+)
+
+type ListPullsOpts struct {
+	// State is one of PullStateOpen (default), PullStateClosed, PullStateAll.
+	State string
+	// Head filters by head user or org and branch, in the format "user:ref-name".
+	Head string
+	// Base filters by base branch name.
+	Base string
+	// Sort is one of PullSortCreated (default), PullSortUpdated,
+	// PullSortPopularity, PullSortLongRunning.
+	Sort string
+	// Direction is DirectionAsc or DirectionDesc;
+	// the default is DirectionDesc when the sort is created or not specified,
+	// DirectionAsc otherwise.
+	Direction string
+
+	// The time windows are inclusive; a zero value means no bound.
+	// The API does not filter by date: the pulls outside the windows are skipped,
+	// and the listing stops early when the pulls are sorted by the same date.
+	CreatedSince time.Time
+	CreatedUntil time.Time
+	UpdatedSince time.Time
+	UpdatedUntil time.Time
+
+	Limit int
+}
+
+// Validate validates ListPullsOpts.
+func (opts *ListPullsOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	switch opts.State {
+	case "", PullStateOpen, PullStateClosed, PullStateAll:
+	default:
+		return fmt.Errorf("opts.State is not valid: %q", opts.State)
+	}
+	switch opts.Sort {
+	case "", PullSortCreated, PullSortUpdated, PullSortPopularity, PullSortLongRunning:
+	default:
+		return fmt.Errorf("opts.Sort is not valid: %q", opts.Sort)
+	}
+	switch opts.Direction {
+	case "", DirectionAsc, DirectionDesc:
+	default:
+		return fmt.Errorf("opts.Direction is not valid: %q", opts.Direction)
+	}
+	if !opts.CreatedSince.IsZero() && !opts.CreatedUntil.IsZero() && opts.CreatedUntil.Before(opts.CreatedSince) {
+		return errors.New("opts.CreatedUntil is before opts.CreatedSince.")
+	}
+	if !opts.UpdatedSince.IsZero() && !opts.UpdatedUntil.IsZero() && opts.UpdatedUntil.Before(opts.UpdatedSince) {
+		return errors.New("opts.UpdatedUntil is before opts.UpdatedSince.")
+	}
+	return nil
+}
+
+func (opts *ListPullsOpts) direction() string {
+	if opts.Direction != "" {
+		return opts.Direction
+	}
+	if opts.Sort == "" || opts.Sort == PullSortCreated {
+		return DirectionDesc
+	}
+	return DirectionAsc
+}
+
+func isInTimeWindow(t time.Time, since time.Time, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !until.IsZero() && t.After(until) {
+		return false
+	}
+	return true
+}
+
+// isInWindow tells whether the pull is in the time windows of the opts.
+func (opts *ListPullsOpts) isInWindow(pull *github.PullRequest) bool {
+	return isInTimeWindow(pull.GetCreatedAt(), opts.CreatedSince, opts.CreatedUntil) &&
+		isInTimeWindow(pull.GetUpdatedAt(), opts.UpdatedSince, opts.UpdatedUntil)
+}
+
+// isPastWindow tells whether the pull, and all the ones after it,
+// are outside the time windows, given the sort order.
+func (opts *ListPullsOpts) isPastWindow(pull *github.PullRequest) bool {
+	var t, since, until time.Time
+	switch opts.Sort {
+	case "", PullSortCreated:
+		t, since, until = pull.GetCreatedAt(), opts.CreatedSince, opts.CreatedUntil
+	case PullSortUpdated:
+		t, since, until = pull.GetUpdatedAt(), opts.UpdatedSince, opts.UpdatedUntil
+	default:
+		return false
+	}
+	if opts.direction() == DirectionDesc {
+		return !since.IsZero() && t.Before(since)
+	}
+	return !until.IsZero() && t.After(until)
+}
+
+// ListPullRequests lists the pull requests of a repo.
+func (c *Client) ListPullRequests(owner string, repo string, opts *ListPullsOpts) ([]*github.PullRequest, error) {
+	var allPRs []*github.PullRequest
+	err := c.StreamPullRequests(owner, repo, opts, func(pull *github.PullRequest) error {
+		allPRs = append(allPRs, pull)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allPRs, nil
+}
+
+// StreamPullRequests is like ListPullRequests, but each pull request is passed
+// to the callback as soon as its page is received.
+// If the callback returns an error, the listing stops and the error is returned.
+func (c *Client) StreamPullRequests(
+	owner string,
+	repo string,
+	opts *ListPullsOpts,
+	callback func(pull *github.PullRequest) error,
+) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	client := c.client
+
+	options := &github.PullRequestListOptions{
+		State:     opts.State,
+		Head:      opts.Head,
+		Base:      opts.Base,
+		Sort:      opts.Sort,
+		Direction: opts.Direction,
+	}
+
+	opt := &github.ListOptions{PerPage: 100}
+	// get all pages of results
+	var count int
+	for {
+
+		var pulls []*github.PullRequest
+		var resp *github.Response
+		errs := RetryExponentialBackoff(5, time.Second, func() error {
+			var err error
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+
+			options.ListOptions = *opt
+			pulls, resp, err = client.PullRequests.List(ctx, owner, repo, options)
+			if err != nil {
+				return fmt.Errorf("error while executing request: %w", err)
+			}
+			onResponse(resp)
+			if handleRateLimitError(err, resp) {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+				// TODO: catch rate limit error, and wait
+				return fmt.Errorf(
+					"status code is: %v (%s)",
+					resp.StatusCode,
+					resp.Status,
+				)
+			}
+			// nil on 200 and 404
+			return nil
+		})
+		if errs != nil && len(errs) > 0 {
+			return errors.New(FormatErrorArray("", errs))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+
+		for _, pull := range pulls {
+			if opts.isPastWindow(pull) {
+				return nil
+			}
+			if !opts.isInWindow(pull) {
+				continue
+			}
+			if err := callback(pull); err != nil {
+				return err
+			}
+			count++
+			if opts.Limit > 0 && count >= opts.Limit {
+				return nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
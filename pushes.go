@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// mediaTypeListPullsOrBranchesForCommitPreview is needed
+// to list the pull requests associated with a commit.
+const mediaTypeListPullsOrBranchesForCommitPreview = "application/vnd.github.groot-preview+json"
+
+// webFlowLogin is the committer of the commits created via the web UI.
+const webFlowLogin = "web-flow"
+
+// ListPullsOfCommit lists the pull requests associated with the commit:
+// the merged ones that contain it, or the open ones whose head contains it.
+func (c *Client) ListPullsOfCommit(owner string, repo string, sha string) ([]*github.PullRequest, error) {
+	var all []*github.PullRequest
+	var page []*github.PullRequest
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("repos/%v/%v/commits/%v/pulls", owner, repo, sha)
+			u, err := addOptions(u, opt)
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Accept", mediaTypeListPullsOrBranchesForCommitPreview)
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// DirectPush is a commit that landed on a branch without a pull request.
+type DirectPush struct {
+	Commit *github.RepositoryCommit
+	// Pusher is the login of who pushed the commit: the committer,
+	// or the author for the commits created via the web UI
+	// (the API does not tell who pushed a commit; the committer is the best guess).
+	Pusher string
+	// ViaWebUI tells whether the commit was created via the web UI.
+	ViaWebUI bool
+}
+
+// FindDirectPushes returns the commits of the branch (empty means the default branch)
+// of the last window (<= 0 means all the history)
+// that are not associated with any pull request merged into that branch, oldest first
+// (a commit pushed directly and later carried by a pull request into another branch,
+// e.g. a release or a backport, is still a direct push).
+// NOTE: each commit costs one extra request.
+func (c *Client) FindDirectPushes(owner string, repo string, branch string, window time.Duration) ([]*DirectPush, error) {
+	if branch == "" {
+		repository, err := c.GetRepo(owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("error while GetRepo: %w", err)
+		}
+		branch = repository.GetDefaultBranch()
+	}
+	commits, err := c.ListCommits(owner, repo, &ListCommitsOpts{
+		SHA:   branch,
+		Since: sinceMaxAge(window),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while ListCommits: %w", err)
+	}
+
+	var pushes []*DirectPush
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		pulls, err := c.ListPullsOfCommit(owner, repo, commit.GetSHA())
+		if err != nil {
+			return nil, fmt.Errorf("error while listing pulls of commit %s: %w", commit.GetSHA(), err)
+		}
+		if hasMergedPull(pulls, branch) {
+			continue
+		}
+
+		push := &DirectPush{
+			Commit: commit,
+			Pusher: commit.GetCommitter().GetLogin(),
+		}
+		if push.Pusher == webFlowLogin {
+			push.ViaWebUI = true
+			push.Pusher = commit.GetAuthor().GetLogin()
+		}
+		pushes = append(pushes, push)
+	}
+	return pushes, nil
+}
+
+// hasMergedPull tells whether one of the pulls was merged into the base branch.
+func hasMergedPull(pulls []*github.PullRequest, base string) bool {
+	for _, pull := range pulls {
+		if pull.MergedAt != nil && pull.GetBase().GetRef() == base {
+			return true
+		}
+	}
+	return false
+}
+
+// DirectPushers groups the direct pushes by pusher.
+func DirectPushers(pushes []*DirectPush) map[string][]*DirectPush {
+	pushers := make(map[string][]*DirectPush)
+	for _, push := range pushes {
+		pushers[push.Pusher] = append(pushers[push.Pusher], push)
+	}
+	return pushers
+}
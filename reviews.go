@@ -0,0 +1,345 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+const (
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+	ReviewStateDismissed        = "DISMISSED"
+	ReviewStatePending          = "PENDING"
+)
+
+// PullReviewMetrics are the review metrics of a pull request.
+type PullReviewMetrics struct {
+	Repo      string
+	Number    int
+	Author    string
+	CreatedAt time.Time
+	Merged    bool
+	MergedAt  time.Time
+
+	// Reviewed tells whether anyone other than the author submitted a review.
+	Reviewed bool
+	// TimeToFirstReview is from the creation to the first review (0 if not reviewed).
+	TimeToFirstReview time.Duration
+	// TimeToMerge is from the creation to the merge (0 if not merged).
+	TimeToMerge time.Duration
+	// ReviewRounds is the number of distinct commits that were reviewed.
+	ReviewRounds int
+	Reviewers    []string
+
+	ReviewCounts
+}
+
+// ReviewCounts are the numbers of submitted reviews, by state.
+type ReviewCounts struct {
+	Approvals      int
+	ChangeRequests int
+	Comments       int
+	// Dismissed are the approvals and change requests that were dismissed
+	// (GitHub does not report their original state).
+	Dismissed int
+}
+
+// count counts a review with the provided state.
+func (c *ReviewCounts) count(state string) {
+	switch state {
+	case ReviewStateApproved:
+		c.Approvals++
+	case ReviewStateChangesRequested:
+		c.ChangeRequests++
+	case ReviewStateCommented:
+		c.Comments++
+	case ReviewStateDismissed:
+		c.Dismissed++
+	}
+}
+
+func (c *ReviewCounts) add(other ReviewCounts) {
+	c.Approvals += other.Approvals
+	c.ChangeRequests += other.ChangeRequests
+	c.Comments += other.Comments
+	c.Dismissed += other.Dismissed
+}
+
+// UnreviewedMerge tells whether the pull request was merged without any review.
+func (m *PullReviewMetrics) UnreviewedMerge() bool {
+	return m.Merged && !m.Reviewed
+}
+
+// PullReviewMetricsOf computes the review metrics of a pull request from its reviews.
+// The reviews of the author and the pending reviews are ignored.
+func PullReviewMetricsOf(repo string, pull *github.PullRequest, reviews []*github.PullRequestReview) *PullReviewMetrics {
+	m := &PullReviewMetrics{
+		Repo:      repo,
+		Number:    pull.GetNumber(),
+		Author:    pull.GetUser().GetLogin(),
+		CreatedAt: pull.GetCreatedAt(),
+		Merged:    pull.MergedAt != nil,
+		MergedAt:  pull.GetMergedAt(),
+	}
+	if m.Merged {
+		m.TimeToMerge = m.MergedAt.Sub(m.CreatedAt)
+	}
+
+	var first time.Time
+	commits := make(map[string]bool)
+	reviewers := make(map[string]bool)
+	for _, review := range reviews {
+		reviewer := review.GetUser().GetLogin()
+		if review.GetState() == ReviewStatePending || strings.EqualFold(reviewer, m.Author) {
+			continue
+		}
+		m.Reviewed = true
+		if submitted := review.GetSubmittedAt(); first.IsZero() || submitted.Before(first) {
+			first = submitted
+		}
+		commits[review.GetCommitID()] = true
+		if !reviewers[reviewer] {
+			reviewers[reviewer] = true
+			m.Reviewers = append(m.Reviewers, reviewer)
+		}
+		m.count(review.GetState())
+	}
+	if m.Reviewed {
+		m.TimeToFirstReview = first.Sub(m.CreatedAt)
+	}
+	m.ReviewRounds = len(commits)
+	return m
+}
+
+// DurationStats summarizes a set of durations.
+type DurationStats struct {
+	Count  int
+	Mean   time.Duration
+	Median time.Duration
+	P90    time.Duration
+	Max    time.Duration
+}
+
+func newDurationStats(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return DurationStats{
+		Count:  len(sorted),
+		Mean:   sum / time.Duration(len(sorted)),
+		Median: sorted[len(sorted)/2],
+		P90:    sorted[(len(sorted)*9)/10],
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// ReviewStats aggregates the review metrics of a group of pull requests
+// (of a repo or an author).
+type ReviewStats struct {
+	Pulls            int
+	Merged           int
+	Reviewed         int
+	UnreviewedMerges int
+
+	ReviewCounts
+	ReviewRounds int
+
+	TimeToFirstReview DurationStats
+	TimeToMerge       DurationStats
+
+	timesToFirstReview []time.Duration
+	timesToMerge       []time.Duration
+}
+
+func (s *ReviewStats) add(m *PullReviewMetrics) {
+	s.Pulls++
+	if m.Merged {
+		s.Merged++
+		s.timesToMerge = append(s.timesToMerge, m.TimeToMerge)
+	}
+	if m.Reviewed {
+		s.Reviewed++
+		s.timesToFirstReview = append(s.timesToFirstReview, m.TimeToFirstReview)
+	}
+	if m.UnreviewedMerge() {
+		s.UnreviewedMerges++
+	}
+	s.ReviewCounts.add(m.ReviewCounts)
+	s.ReviewRounds += m.ReviewRounds
+}
+
+func (s *ReviewStats) finalize() {
+	s.TimeToFirstReview = newDurationStats(s.timesToFirstReview)
+	s.TimeToMerge = newDurationStats(s.timesToMerge)
+}
+
+// ReviewerStats aggregates the reviews done by a reviewer.
+type ReviewerStats struct {
+	// Pulls is the number of pull requests reviewed.
+	Pulls int
+	ReviewCounts
+	// TimeToReview is from the creation of each pull request
+	// to the first review of the reviewer.
+	TimeToReview DurationStats
+
+	timesToReview []time.Duration
+}
+
+// ReviewAnalytics are the review metrics of the pull requests
+// created in a time window.
+type ReviewAnalytics struct {
+	Since time.Time
+	Until time.Time
+
+	Total      ReviewStats
+	ByRepo     map[string]*ReviewStats
+	ByAuthor   map[string]*ReviewStats
+	ByReviewer map[string]*ReviewerStats
+
+	Pulls []*PullReviewMetrics
+}
+
+type ReviewAnalyticsOpts struct {
+	// Since and Until are the window of the creation date of the pull requests;
+	// a zero value means no bound.
+	Since time.Time
+	Until time.Time
+	// Base, if set, restricts the pull requests to the ones against the base branch.
+	Base string
+	// Concurrency is the number of pull requests whose reviews are fetched
+	// at the same time; values <= 1 mean one at a time.
+	Concurrency int
+}
+
+// Validate validates ReviewAnalyticsOpts.
+func (opts *ReviewAnalyticsOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && opts.Until.Before(opts.Since) {
+		return errors.New("opts.Until is before opts.Since.")
+	}
+	return nil
+}
+
+// AnalyzeReviews computes the review metrics of the pull requests
+// (open, closed and merged) of the repos of the owner.
+// NOTE: each pull request costs one extra request.
+func (c *Client) AnalyzeReviews(owner string, repos []string, opts *ReviewAnalyticsOpts) (*ReviewAnalytics, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	analytics := &ReviewAnalytics{
+		Since:      opts.Since,
+		Until:      opts.Until,
+		ByRepo:     make(map[string]*ReviewStats),
+		ByAuthor:   make(map[string]*ReviewStats),
+		ByReviewer: make(map[string]*ReviewerStats),
+	}
+	for _, repo := range repos {
+		pulls, err := c.ListPullRequests(owner, repo, &ListPullsOpts{
+			State:        PullStateAll,
+			Base:         opts.Base,
+			Sort:         PullSortCreated,
+			Direction:    DirectionDesc,
+			CreatedSince: opts.Since,
+			CreatedUntil: opts.Until,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error while listing pulls of %s/%s: %w", owner, repo, err)
+		}
+
+		reviews := make([][]*github.PullRequestReview, len(pulls))
+		group := NewSizedGroup(int64(concurrency))
+		for i := range pulls {
+			index := i
+			group.Go(func() error {
+				number := pulls[index].GetNumber()
+				pullReviews, err := c.ListPullReviews(owner, repo, number)
+				if err != nil {
+					return fmt.Errorf("error while listing reviews of %s/%s#%d: %w", owner, repo, number, err)
+				}
+				reviews[index] = pullReviews
+				return nil
+			})
+		}
+		err = group.Wait()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, pull := range pulls {
+			analytics.add(PullReviewMetricsOf(repo, pull, reviews[i]), reviews[i])
+		}
+	}
+
+	analytics.Total.finalize()
+	for _, stats := range analytics.ByRepo {
+		stats.finalize()
+	}
+	for _, stats := range analytics.ByAuthor {
+		stats.finalize()
+	}
+	for _, stats := range analytics.ByReviewer {
+		stats.TimeToReview = newDurationStats(stats.timesToReview)
+	}
+	return analytics, nil
+}
+
+func (a *ReviewAnalytics) add(m *PullReviewMetrics, reviews []*github.PullRequestReview) {
+	a.Pulls = append(a.Pulls, m)
+	a.Total.add(m)
+
+	if a.ByRepo[m.Repo] == nil {
+		a.ByRepo[m.Repo] = &ReviewStats{}
+	}
+	a.ByRepo[m.Repo].add(m)
+
+	if a.ByAuthor[m.Author] == nil {
+		a.ByAuthor[m.Author] = &ReviewStats{}
+	}
+	a.ByAuthor[m.Author].add(m)
+
+	firstReviews := make(map[string]time.Time)
+	for _, review := range reviews {
+		reviewer := review.GetUser().GetLogin()
+		if review.GetState() == ReviewStatePending || strings.EqualFold(reviewer, m.Author) {
+			continue
+		}
+		stats := a.ByReviewer[reviewer]
+		if stats == nil {
+			stats = &ReviewerStats{}
+			a.ByReviewer[reviewer] = stats
+		}
+		stats.count(review.GetState())
+		if first, ok := firstReviews[reviewer]; !ok || review.GetSubmittedAt().Before(first) {
+			firstReviews[reviewer] = review.GetSubmittedAt()
+		}
+	}
+	for reviewer, first := range firstReviews {
+		stats := a.ByReviewer[reviewer]
+		stats.Pulls++
+		stats.timesToReview = append(stats.timesToReview, first.Sub(m.CreatedAt))
+	}
+}
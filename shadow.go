@@ -0,0 +1,438 @@
+package github
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// CommitClassification is how a commit landed in a repo.
+type CommitClassification string
+
+const (
+	// CommitDirect is a commit committed by its author (i.e. pushed by someone with push access).
+	CommitDirect CommitClassification = "direct"
+	// CommitWebFlowMerge is a commit created via the web UI (e.g. a pull request merged on GitHub).
+	CommitWebFlowMerge CommitClassification = "web-flow-merge"
+	// CommitModeratedPR is a commit committed by someone other than its author
+	// (e.g. a pull request merged locally by a maintainer).
+	CommitModeratedPR CommitClassification = "moderated-pr"
+)
+
+// ClassifyCommit classifies how the commit landed in the repo,
+// by its author and committer only: a pull request merged locally
+// by its own author is CommitDirect (see ShadowEvidence.Pull).
+func ClassifyCommit(commit *github.RepositoryCommit) CommitClassification {
+	switch {
+	case commit.GetCommitter().GetLogin() == webFlowLogin:
+		return CommitWebFlowMerge
+	case isDirectCommit(commit):
+		return CommitDirect
+	default:
+		return CommitModeratedPR
+	}
+}
+
+// ShadowEvidence is a commit that is evidence of the activity of a contributor.
+type ShadowEvidence struct {
+	Repo           string               `json:"repo"`
+	SHA            string               `json:"sha"`
+	URL            string               `json:"url"`
+	Date           time.Time            `json:"date"`
+	Subject        string               `json:"subject"`
+	Classification CommitClassification `json:"classification"`
+	// Pull is the number of the pull request the commit merges or squashes,
+	// according to the default merge messages of GitHub (0 if none);
+	// it is only a hint (see PullMergeOfCommit), unless MergeMethod is set.
+	Pull int `json:"pull,omitempty"`
+	// MergeMethod is how Pull was merged, confirmed with DetectMergeMethod;
+	// only set with ShadowMemberOpts.ConfirmMergeMethod.
+	MergeMethod MergeMethod `json:"merge_method,omitempty"`
+}
+
+// ShadowMember is a contributor flagged as a shadow member, with the reasons.
+type ShadowMember struct {
+	Login string `json:"login"`
+	// Contributions is the number of contributions reported by GitHub.
+	Contributions int `json:"contributions"`
+
+	Evidence      []*ShadowEvidence `json:"evidence"`
+	Direct        int               `json:"direct"`
+	WebFlowMerges int               `json:"web_flow_merges"`
+	ModeratedPRs  int               `json:"moderated_prs"`
+
+	OfficialMember      bool `json:"official_member"`
+	OutsideCollaborator bool `json:"outside_collaborator"`
+
+	FirstActivity time.Time `json:"first_activity"`
+	LastActivity  time.Time `json:"last_activity"`
+
+	// Confidence (0 to 1) that the user has push access without being an official member:
+	// 0 for official members, 1 for outside collaborators,
+	// otherwise it grows with the number of direct commits.
+	Confidence float64 `json:"confidence"`
+}
+
+// ShadowMemberReport is the explained result of the shadow-member analysis of a repo.
+type ShadowMemberReport struct {
+	Owner       string    `json:"owner"`
+	Repo        string    `json:"repo"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// OutsideCollaboratorsError is set when the outside collaborators
+	// could not be listed (only the owners of the org can list them).
+	OutsideCollaboratorsError string `json:"outside_collaborators_error,omitempty"`
+	// Incomplete is set when the analysis was interrupted by IsExitingFunc;
+	// Members has only the contributors analyzed until then.
+	Incomplete bool `json:"incomplete,omitempty"`
+
+	Members []*ShadowMember `json:"members"`
+}
+
+// ExplainShadowMembers is like FindShadowMembersByContributions, but for each
+// flagged contributor it reports the evidence and the membership status.
+func (c *Client) ExplainShadowMembers(owner string, repo string, opts *ShadowMemberOpts) (*ShadowMemberReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	membership, err := c.getShadowMembership(owner)
+	if err != nil {
+		return nil, err
+	}
+	members, err := c.findShadowMembersInRepo(owner, repo, opts, membership)
+	if err != nil && err != errInterrupted {
+		return nil, err
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Confidence > members[j].Confidence
+	})
+	return &ShadowMemberReport{
+		Owner:                     owner,
+		Repo:                      repo,
+		GeneratedAt:               time.Now(),
+		OutsideCollaboratorsError: membership.outsideErr,
+		Incomplete:                err == errInterrupted,
+		Members:                   members,
+	}, nil
+}
+
+// shadowMembership is the membership status of the users of an owner.
+type shadowMembership struct {
+	official   map[string]bool
+	outside    map[string]bool
+	outsideErr string
+}
+
+func (m *shadowMembership) isOfficial(login string) bool {
+	return m.official[strings.ToLower(login)]
+}
+
+func (m *shadowMembership) isOutside(login string) bool {
+	return m.outside[strings.ToLower(login)]
+}
+
+func (c *Client) getShadowMembership(owner string) (*shadowMembership, error) {
+	membership := &shadowMembership{
+		official: make(map[string]bool),
+		outside:  make(map[string]bool),
+	}
+	members, err := c.ListOfficialMembers(owner)
+	switch {
+	case err == ErrNotFound:
+		// the owner is a user.
+		membership.official[strings.ToLower(owner)] = true
+	case err != nil:
+		return nil, fmt.Errorf("error while ListOfficialMembers: %w", err)
+	default:
+		for _, member := range members {
+			membership.official[strings.ToLower(member.GetLogin())] = true
+		}
+		collaborators, err := c.ListOutsideCollaborators(owner, OutsideCollaboratorsFilterAll)
+		if err != nil {
+			membership.outsideErr = err.Error()
+		}
+		for _, collaborator := range collaborators {
+			membership.outside[strings.ToLower(collaborator.GetLogin())] = true
+		}
+	}
+	return membership, nil
+}
+
+// ShadowAlgorithm is how the shadow-member analysis gets the commits of each contributor.
+type ShadowAlgorithm int
+
+const (
+	// ShadowAlgorithmPerContributor lists the commits of each contributor
+	// (one listing per contributor).
+	ShadowAlgorithmPerContributor ShadowAlgorithm = iota
+	// ShadowAlgorithmSinglePass lists the commits of the time window once,
+	// and groups them by author in memory; it costs fewer requests
+	// when there are many contributors and the window is not too long.
+	ShadowAlgorithmSinglePass
+)
+
+type ShadowMemberOpts struct {
+	// MaxAge is the age of the commits considered; <= 0 means all the history.
+	MaxAge time.Duration
+	// Algorithm defaults to ShadowAlgorithmPerContributor;
+	// both algorithms give the same result.
+	Algorithm ShadowAlgorithm
+	// ConfirmMergeMethod, if set, confirms the pull request of each evidence
+	// (see ShadowEvidence.Pull), and detects how it was merged;
+	// it costs a few requests per pull request.
+	ConfirmMergeMethod bool
+}
+
+// Validate validates ShadowMemberOpts.
+func (opts *ShadowMemberOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	switch opts.Algorithm {
+	case ShadowAlgorithmPerContributor, ShadowAlgorithmSinglePass:
+	default:
+		return fmt.Errorf("opts.Algorithm is not valid: %v", opts.Algorithm)
+	}
+	return nil
+}
+
+// authorCommitsFunc returns the commits of the author (a login).
+type authorCommitsFunc func(login string) ([]*github.RepositoryCommit, error)
+
+// newAuthorCommitsFunc returns the function that gets the commits of each author
+// of the time window, with the algorithm.
+func (c *Client) newAuthorCommitsFunc(owner string, repo string, maxAge time.Duration, algorithm ShadowAlgorithm) (authorCommitsFunc, error) {
+	if algorithm != ShadowAlgorithmSinglePass {
+		return func(login string) ([]*github.RepositoryCommit, error) {
+			return c.ListCommitsByAuthor(owner, repo, login, maxAge)
+		}, nil
+	}
+
+	// same window as ListCommitsByAuthor:
+	commits, err := c.ListCommits(
+		owner,
+		repo,
+		&ListCommitsOpts{
+			Since:     sinceMaxAge(maxAge),
+			DateField: AuthorDate,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListCommits: %w", err)
+	}
+	// The commits are bucketed by author only, like ListCommitsByAuthor lists them:
+	// a commit of which the contributor is only the committer is never direct
+	// for them (author != committer), so it could not change the result,
+	// and it would only add evidence that the other algorithm does not have.
+	byAuthor := make(map[string][]*github.RepositoryCommit)
+	for _, commit := range commits {
+		login := strings.ToLower(commit.GetAuthor().GetLogin())
+		if login == "" {
+			continue
+		}
+		byAuthor[login] = append(byAuthor[login], commit)
+	}
+	return func(login string) ([]*github.RepositoryCommit, error) {
+		return byAuthor[strings.ToLower(login)], nil
+	}, nil
+}
+
+// errInterrupted is returned with the partial results
+// when IsExitingFunc tells to stop.
+var errInterrupted = errors.New("interrupted")
+
+// findShadowMembersInRepo returns the explained shadow members of the repo.
+func (c *Client) findShadowMembersInRepo(
+	owner string,
+	repo string,
+	opts *ShadowMemberOpts,
+	membership *shadowMembership,
+) ([]*ShadowMember, error) {
+	contributors, err := c.ListContributors(owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListContributors: %w", err)
+	}
+	if len(contributors) == 0 {
+		// e.g. an empty repo, of which the commits can't be listed (409).
+		return nil, nil
+	}
+	commitsByAuthor, err := c.newAuthorCommitsFunc(owner, repo, opts.MaxAge, opts.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var members []*ShadowMember
+	for _, contributor := range contributors {
+		if IsExitingFunc != nil && IsExitingFunc() {
+			return members, errInterrupted
+		}
+		login := contributor.GetLogin()
+		commits, err := commitsByAuthor(login)
+		if err != nil {
+			return nil, fmt.Errorf("error while ListCommitsByAuthor for %s: %s", login, err)
+		}
+		if !isShadowMember(commits) {
+			continue
+		}
+		member := newShadowMember(repo, contributor, commits)
+		if opts.ConfirmMergeMethod {
+			for _, evidence := range member.Evidence {
+				if err := c.confirmPullMerge(owner, repo, evidence); err != nil {
+					return nil, fmt.Errorf("error while confirming the merge of %s: %w", evidence.SHA, err)
+				}
+			}
+		}
+		member.OfficialMember = membership.isOfficial(login)
+		member.OutsideCollaborator = membership.isOutside(login)
+		member.Confidence = member.confidence()
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func newShadowMember(repo string, contributor *github.Contributor, commits []*github.RepositoryCommit) *ShadowMember {
+	member := &ShadowMember{
+		Login:         contributor.GetLogin(),
+		Contributions: contributor.GetContributions(),
+	}
+	for _, commit := range commits {
+		evidence := &ShadowEvidence{
+			Repo:           repo,
+			SHA:            commit.GetSHA(),
+			URL:            commit.GetHTMLURL(),
+			Date:           commit.GetCommit().GetAuthor().GetDate(),
+			Subject:        strings.SplitN(commit.GetCommit().GetMessage(), "\n", 2)[0],
+			Classification: ClassifyCommit(commit),
+		}
+		evidence.Pull, _, _ = PullMergeOfCommit(commit)
+		member.addEvidence(evidence)
+	}
+	return member
+}
+
+// confirmPullMerge checks that the pull request of the evidence (if any)
+// was merged as the commit of the evidence, and sets its merge method;
+// when it was not, the hint was wrong, and the pull request is removed.
+func (c *Client) confirmPullMerge(owner string, repo string, evidence *ShadowEvidence) error {
+	if evidence.Pull == 0 {
+		return nil
+	}
+	pull, err := c.GetPull(owner, repo, evidence.Pull)
+	if err == ErrNotFound {
+		// e.g. a reference to an issue.
+		evidence.Pull = 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pull.MergedAt == nil || !strings.EqualFold(pull.GetMergeCommitSHA(), evidence.SHA) {
+		evidence.Pull = 0
+		return nil
+	}
+	commits, err := c.ListPullCommits(owner, repo, evidence.Pull)
+	if err != nil {
+		return fmt.Errorf("error while listing commits: %w", err)
+	}
+	evidence.MergeMethod, err = c.detectMergeMethod(owner, repo, pull, commits)
+	return err
+}
+
+func (m *ShadowMember) addEvidence(evidence *ShadowEvidence) {
+	m.Evidence = append(m.Evidence, evidence)
+	switch evidence.Classification {
+	case CommitDirect:
+		m.Direct++
+	case CommitWebFlowMerge:
+		m.WebFlowMerges++
+	case CommitModeratedPR:
+		m.ModeratedPRs++
+	}
+	if m.FirstActivity.IsZero() || evidence.Date.Before(m.FirstActivity) {
+		m.FirstActivity = evidence.Date
+	}
+	if evidence.Date.After(m.LastActivity) {
+		m.LastActivity = evidence.Date
+	}
+}
+
+func (m *ShadowMember) confidence() float64 {
+	switch {
+	case m.OfficialMember:
+		return 0
+	case m.OutsideCollaborator:
+		return 1
+	}
+	// each direct commit halves the doubt.
+	return 1 - math.Pow(0.5, float64(m.Direct))
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *ShadowMemberReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+var shadowMemberCSVHeader = []string{
+	"login",
+	"official_member",
+	"outside_collaborator",
+	"contributions",
+	"direct",
+	"web_flow_merges",
+	"moderated_prs",
+	"first_activity",
+	"last_activity",
+	"confidence",
+	"evidence",
+}
+
+// WriteCSV writes the report as CSV, one row per member;
+// the evidence column contains the SHAs of the direct commits.
+func (r *ShadowMemberReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(shadowMemberCSVHeader); err != nil {
+		return err
+	}
+	for _, m := range r.Members {
+		var shas []string
+		for _, evidence := range m.Evidence {
+			if evidence.Classification == CommitDirect {
+				shas = append(shas, evidence.SHA)
+			}
+		}
+		err := writer.Write([]string{
+			m.Login,
+			strconv.FormatBool(m.OfficialMember),
+			strconv.FormatBool(m.OutsideCollaborator),
+			strconv.Itoa(m.Contributions),
+			strconv.Itoa(m.Direct),
+			strconv.Itoa(m.WebFlowMerges),
+			strconv.Itoa(m.ModeratedPRs),
+			formatCSVTime(m.FirstActivity),
+			formatCSVTime(m.LastActivity),
+			strconv.FormatFloat(m.Confidence, 'f', 2, 64),
+			strings.Join(shas, " "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestConfirmPullMerge(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/7":
+			fmt.Fprint(w, `{"number": 7, "merged": true, "merged_at": "2020-01-02T00:00:00Z", "merge_commit_sha": "squashed"}`)
+		case "/repos/owner/repo/pulls/7/commits":
+			fmt.Fprint(w, `[
+				{"sha": "a", "commit": {"message": "First"}},
+				{"sha": "b", "commit": {"message": "Second"}}
+			]`)
+		case "/repos/owner/repo/commits":
+			fmt.Fprint(w, `[
+				{"sha": "squashed", "commit": {"message": "Feature (#7)"}, "parents": [{"sha": "base"}]},
+				{"sha": "base", "commit": {"message": "Base"}, "parents": [{"sha": "older"}]},
+				{"sha": "older", "commit": {"message": "Older"}, "parents": []}
+			]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	tests := []struct {
+		name        string
+		evidence    *ShadowEvidence
+		pull        int
+		mergeMethod MergeMethod
+	}{
+		{
+			name:        "squashed",
+			evidence:    &ShadowEvidence{SHA: "squashed", Pull: 7},
+			pull:        7,
+			mergeMethod: MergeMethodSquash,
+		},
+		{
+			name:     "merged as another commit",
+			evidence: &ShadowEvidence{SHA: "other", Pull: 7},
+		},
+		{
+			name:     "not a pull request",
+			evidence: &ShadowEvidence{SHA: "issue", Pull: 8},
+		},
+		{
+			name:     "no pull request",
+			evidence: &ShadowEvidence{SHA: "direct"},
+		},
+	}
+	for _, tt := range tests {
+		if err := client.confirmPullMerge("owner", "repo", tt.evidence); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if tt.evidence.Pull != tt.pull || tt.evidence.MergeMethod != tt.mergeMethod {
+			t.Errorf("%s: got pull %d (%q), expected %d (%q)", tt.name, tt.evidence.Pull, tt.evidence.MergeMethod, tt.pull, tt.mergeMethod)
+		}
+	}
+}
+
+func TestShadowMembersOfEmptyRepo(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/owner/members", "/orgs/owner/outside_collaborators":
+			fmt.Fprint(w, `[]`)
+		case "/repos/owner/repo/contributors":
+			w.WriteHeader(http.StatusNoContent)
+		case "/repos/owner/repo/commits":
+			http.Error(w, `{"message": "Git Repository is empty."}`, http.StatusConflict)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	for _, algorithm := range []ShadowAlgorithm{ShadowAlgorithmPerContributor, ShadowAlgorithmSinglePass} {
+		opts := &ShadowMemberOpts{Algorithm: algorithm}
+		report, err := client.ExplainShadowMembers("owner", "repo", opts)
+		if err != nil {
+			t.Errorf("algorithm %v: unexpected error: %v", algorithm, err)
+		} else if len(report.Members) != 0 || report.Incomplete {
+			t.Errorf("algorithm %v: got %d members (incomplete: %v), expected none", algorithm, len(report.Members), report.Incomplete)
+		}
+
+		members, err := client.FindShadowMembersByContributionsWithOpts("owner", "repo", opts)
+		if err != nil {
+			t.Errorf("algorithm %v: unexpected error: %v", algorithm, err)
+		} else if len(members) != 0 {
+			t.Errorf("algorithm %v: got %d members, expected none", algorithm, len(members))
+		}
+	}
+}
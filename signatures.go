@@ -0,0 +1,424 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	SignatureTypeNone = "none"
+	SignatureTypeGPG  = "gpg"
+	SignatureTypeSSH  = "ssh"
+	SignatureTypeX509 = "x509"
+)
+
+// SignedCommit is the signature info of a commit.
+type SignedCommit struct {
+	SHA    string
+	Branch string
+	// Author is the GitHub login, or the email if not linked to a user.
+	Author string
+	Date   time.Time
+
+	Verified bool
+	// Reason is the verification reason given by GitHub (e.g. "valid", "unsigned", "unknown_key").
+	Reason string
+	// SignatureType is one of SignatureTypeNone, SignatureTypeGPG, SignatureTypeSSH, SignatureTypeX509.
+	SignatureType string
+	// KeyID is the ID of the signing key: the 16-hex-digit key ID for GPG,
+	// the SHA256 fingerprint for SSH; empty if unknown.
+	KeyID string
+}
+
+func (sc *SignedCommit) isSigned() bool {
+	return sc.SignatureType != SignatureTypeNone
+}
+
+// signerOf identifies the signer of the commit, for detecting changes.
+func (sc *SignedCommit) signerOf() string {
+	if !sc.isSigned() {
+		return "unsigned"
+	}
+	if sc.KeyID == "" {
+		return sc.SignatureType
+	}
+	return sc.SignatureType + ":" + sc.KeyID
+}
+
+// SignatureStats counts commits by signature status.
+type SignatureStats struct {
+	Total      int
+	Verified   int
+	Unverified int
+	// Unsigned commits are also counted as Unverified.
+	Unsigned int
+}
+
+func (s *SignatureStats) add(sc *SignedCommit) {
+	s.Total++
+	if sc.Verified {
+		s.Verified++
+	} else {
+		s.Unverified++
+	}
+	if !sc.isSigned() {
+		s.Unsigned++
+	}
+}
+
+// UnsignedShare returns the share (0 to 1) of unsigned commits.
+func (s *SignatureStats) UnsignedShare() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Unsigned) / float64(s.Total)
+}
+
+// SignatureChange is a change of the signer of the commits of an author.
+type SignatureChange struct {
+	Author string
+	// From and To are "unsigned", the signature type, or "type:keyID".
+	From string
+	To   string
+	// Commit is the first commit with the new signer.
+	Commit *SignedCommit
+}
+
+type CommitSignatureReport struct {
+	Total    SignatureStats
+	ByAuthor map[string]*SignatureStats
+	ByBranch map[string]*SignatureStats
+	ByReason map[string]int
+	// KeyIDsByAuthor are the signing keys used by each author.
+	KeyIDsByAuthor map[string][]string
+	// Changes are the changes of signer of each author over time, oldest first.
+	Changes []*SignatureChange
+	// Commits are all the analyzed commits, oldest first.
+	Commits []*SignedCommit
+}
+
+type CommitSignatureReportOpts struct {
+	// Branches to analyze; empty means the default branch.
+	Branches []string
+	// Author is a GitHub login or email; empty means all authors.
+	Author string
+	// Since and Until are the window of the committer date; zero means no bound.
+	Since time.Time
+	Until time.Time
+}
+
+// Validate validates CommitSignatureReportOpts.
+func (opts *CommitSignatureReportOpts) Validate() error {
+	if opts == nil {
+		return errors.New("opts is nil.")
+	}
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && opts.Until.Before(opts.Since) {
+		return errors.New("opts.Until is before opts.Since.")
+	}
+	return nil
+}
+
+// CommitSignatureReport summarizes the signatures of the commits of a repo.
+// A commit that is in more than one branch is counted only once in the totals
+// and per author, but once for each branch in ByBranch.
+func (c *Client) CommitSignatureReport(owner string, repo string, opts *CommitSignatureReportOpts) (*CommitSignatureReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	branches := opts.Branches
+	if len(branches) == 0 {
+		branches = []string{""}
+	}
+
+	report := &CommitSignatureReport{
+		ByAuthor:       make(map[string]*SignatureStats),
+		ByBranch:       make(map[string]*SignatureStats),
+		ByReason:       make(map[string]int),
+		KeyIDsByAuthor: make(map[string][]string),
+	}
+	seen := make(map[string]bool)
+	for _, branch := range branches {
+		commits, err := c.ListCommits(owner, repo, &ListCommitsOpts{
+			SHA:    branch,
+			Author: opts.Author,
+			Since:  opts.Since,
+			Until:  opts.Until,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error while ListCommits for branch %q: %w", branch, err)
+		}
+
+		branchName := branch
+		if branchName == "" {
+			branchName = "(default)"
+		}
+		branchStats := &SignatureStats{}
+		report.ByBranch[branchName] = branchStats
+
+		for _, commit := range commits {
+			sc := newSignedCommit(commit)
+			sc.Branch = branchName
+			branchStats.add(sc)
+
+			if seen[sc.SHA] {
+				continue
+			}
+			seen[sc.SHA] = true
+			report.Commits = append(report.Commits, sc)
+		}
+	}
+
+	sort.SliceStable(report.Commits, func(i, j int) bool {
+		return report.Commits[i].Date.Before(report.Commits[j].Date)
+	})
+
+	lastSigner := make(map[string]string)
+	for _, sc := range report.Commits {
+		report.Total.add(sc)
+		report.ByReason[sc.Reason]++
+
+		if report.ByAuthor[sc.Author] == nil {
+			report.ByAuthor[sc.Author] = &SignatureStats{}
+		}
+		report.ByAuthor[sc.Author].add(sc)
+
+		if sc.KeyID != "" && !containsString(report.KeyIDsByAuthor[sc.Author], sc.KeyID) {
+			report.KeyIDsByAuthor[sc.Author] = append(report.KeyIDsByAuthor[sc.Author], sc.KeyID)
+		}
+
+		signer := sc.signerOf()
+		if previous, ok := lastSigner[sc.Author]; ok && previous != signer {
+			report.Changes = append(report.Changes, &SignatureChange{
+				Author: sc.Author,
+				From:   previous,
+				To:     signer,
+				Commit: sc,
+			})
+		}
+		lastSigner[sc.Author] = signer
+	}
+
+	return report, nil
+}
+
+func containsString(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newSignedCommit(commit *github.RepositoryCommit) *SignedCommit {
+	verification := commit.GetCommit().GetVerification()
+	sc := &SignedCommit{
+		SHA:      commit.GetSHA(),
+		Author:   commitAuthorKey(commit),
+		Date:     commit.GetCommit().GetCommitter().GetDate(),
+		Verified: verification.GetVerified(),
+		Reason:   verification.GetReason(),
+	}
+	sc.SignatureType, sc.KeyID = ParseSignatureKeyID(verification.GetSignature())
+	return sc
+}
+
+// ParseSignatureKeyID returns the type of the signature and the ID of the signing key:
+// the issuer key ID (16 hex digits) for GPG signatures,
+// the SHA256 fingerprint of the public key for SSH signatures.
+// The key ID is empty if it can't be parsed.
+func ParseSignatureKeyID(signature string) (string, string) {
+	signature = strings.TrimSpace(signature)
+	switch {
+	case signature == "":
+		return SignatureTypeNone, ""
+	case strings.HasPrefix(signature, "-----BEGIN PGP SIGNATURE-----"):
+		data, err := decodeArmor(signature)
+		if err != nil {
+			return SignatureTypeGPG, ""
+		}
+		return SignatureTypeGPG, pgpIssuerKeyID(data)
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		data, err := decodeArmor(signature)
+		if err != nil {
+			return SignatureTypeSSH, ""
+		}
+		return SignatureTypeSSH, sshSignatureFingerprint(data)
+	default:
+		return SignatureTypeX509, ""
+	}
+}
+
+// decodeArmor decodes the base64 body of an ASCII-armored block,
+// skipping the armor headers and the checksum.
+func decodeArmor(armored string) ([]byte, error) {
+	lines := strings.Split(strings.Replace(armored, "\r\n", "\n", -1), "\n")
+	var body strings.Builder
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "-----END"):
+			return base64.StdEncoding.DecodeString(body.String())
+		case line == "", strings.Contains(line, ": "):
+			// armor headers.
+		case strings.HasPrefix(line, "=") && len(line) == 5:
+			// checksum.
+		default:
+			body.WriteString(line)
+		}
+	}
+	return nil, errors.New("armor end not found")
+}
+
+// pgpIssuerKeyID returns the issuer key ID of an OpenPGP signature packet
+// (from the issuer or the issuer fingerprint subpacket).
+func pgpIssuerKeyID(data []byte) string {
+	if len(data) < 2 || data[0]&0x80 == 0 {
+		return ""
+	}
+	// packet header:
+	var body []byte
+	if data[0]&0x40 != 0 {
+		// new format.
+		if data[0]&0x3f != 2 {
+			return ""
+		}
+		length, n := pgpNewLength(data[1:])
+		if n == 0 || length < 0 || length > len(data)-1-n {
+			return ""
+		}
+		body = data[1+n : 1+n+length]
+	} else {
+		// old format.
+		if (data[0]>>2)&0x0f != 2 {
+			return ""
+		}
+		var length, n int
+		switch data[0] & 0x03 {
+		case 0:
+			length, n = int(data[1]), 1
+		case 1:
+			if len(data) < 3 {
+				return ""
+			}
+			length, n = int(binary.BigEndian.Uint16(data[1:3])), 2
+		case 2:
+			if len(data) < 5 {
+				return ""
+			}
+			length, n = int(binary.BigEndian.Uint32(data[1:5])), 4
+		default:
+			length, n = len(data)-1, 0
+		}
+		if length < 0 || length > len(data)-1-n {
+			return ""
+		}
+		body = data[1+n : 1+n+length]
+	}
+
+	if len(body) < 1 {
+		return ""
+	}
+	switch body[0] {
+	case 3:
+		// version 3: the key ID is at a fixed offset.
+		if len(body) < 15 {
+			return ""
+		}
+		return strings.ToUpper(hex.EncodeToString(body[7:15]))
+	case 4, 5:
+		if len(body) < 6 {
+			return ""
+		}
+		hashedLen := int(binary.BigEndian.Uint16(body[4:6]))
+		if 6+hashedLen+2 > len(body) {
+			return ""
+		}
+		hashed := body[6 : 6+hashedLen]
+		unhashedLen := int(binary.BigEndian.Uint16(body[6+hashedLen : 8+hashedLen]))
+		if 8+hashedLen+unhashedLen > len(body) {
+			return ""
+		}
+		unhashed := body[8+hashedLen : 8+hashedLen+unhashedLen]
+
+		for _, subpackets := range [][]byte{hashed, unhashed} {
+			if keyID := pgpSubpacketKeyID(subpackets); keyID != "" {
+				return keyID
+			}
+		}
+	}
+	return ""
+}
+
+func pgpNewLength(data []byte) (int, int) {
+	if len(data) < 1 {
+		return 0, 0
+	}
+	switch {
+	case data[0] < 192:
+		return int(data[0]), 1
+	case data[0] < 224:
+		if len(data) < 2 {
+			return 0, 0
+		}
+		return (int(data[0])-192)<<8 + int(data[1]) + 192, 2
+	case data[0] == 255:
+		if len(data) < 5 {
+			return 0, 0
+		}
+		return int(binary.BigEndian.Uint32(data[1:5])), 5
+	}
+	// partial lengths are not used for signatures.
+	return 0, 0
+}
+
+func pgpSubpacketKeyID(subpackets []byte) string {
+	for len(subpackets) > 0 {
+		length, n := pgpNewLength(subpackets)
+		if n == 0 || length < 1 || length > len(subpackets)-n {
+			return ""
+		}
+		packet := subpackets[n : n+length]
+		subpackets = subpackets[n+length:]
+
+		switch packet[0] & 0x7f {
+		case 16:
+			// issuer.
+			if len(packet) == 9 {
+				return strings.ToUpper(hex.EncodeToString(packet[1:9]))
+			}
+		case 33:
+			// issuer fingerprint: the key ID is the last 8 bytes of a v4 fingerprint.
+			if len(packet) == 22 && packet[1] == 4 {
+				return strings.ToUpper(hex.EncodeToString(packet[14:22]))
+			}
+		}
+	}
+	return ""
+}
+
+// sshSignatureFingerprint returns the SHA256 fingerprint of the public key
+// of an SSH signature (see PROTOCOL.sshsig).
+func sshSignatureFingerprint(data []byte) string {
+	magic := []byte("SSHSIG")
+	if !bytes.HasPrefix(data, magic) || len(data) < len(magic)+8 {
+		return ""
+	}
+	rest := data[len(magic)+4:] // skip version.
+	keyLen := int(binary.BigEndian.Uint32(rest[:4]))
+	if keyLen < 0 || keyLen > len(rest)-4 {
+		return ""
+	}
+	sum := sha256.Sum256(rest[4 : 4+keyLen])
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,226 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+// Generated with `gpg --armor --detach-sign` (ed25519 key B7A733318304CB74).
+const testPGPSignature = `-----BEGIN PGP SIGNATURE-----
+
+iHUEABYIAB0WIQRToabwvwGf+qugLnG3pzMxgwTLdAUCatS5zQAKCRC3pzMxgwTL
+dNPGAP4m+RYOhaDaqVw0V59xMSEvjUjppNbYJJznNEP6cuv2gwD/Rabg02TxqOCW
+JmSYb7yfBIVJxr4eolGOJMVtf4tHlgM=
+=5mZj
+-----END PGP SIGNATURE-----`
+
+// Generated with `ssh-keygen -Y sign -n git` (ed25519 key SHA256:hivEkyWdb0Ip7GuBTX45PjRcnUaNINVicD5jYvN0V8U).
+const testSSHSignature = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgJ4oD5mXPVPFxaBNPRXxvMqyhC6
+431JVDredPCizg6RgAAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5
+AAAAQN43Y94EUXmIpoZPeYLIpOq8Xq4yRzHblR7g1VE2evh5X5tRpaekg9t81hflbUHpB/
+DTskEfR+A+8e1R3xXf6AM=
+-----END SSH SIGNATURE-----`
+
+func TestParseSignatureKeyID(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		sigType   string
+		keyID     string
+	}{
+		{name: "empty", signature: "", sigType: SignatureTypeNone},
+		{name: "pgp", signature: testPGPSignature, sigType: SignatureTypeGPG, keyID: "B7A733318304CB74"},
+		{name: "ssh", signature: testSSHSignature, sigType: SignatureTypeSSH, keyID: "SHA256:hivEkyWdb0Ip7GuBTX45PjRcnUaNINVicD5jYvN0V8U"},
+		{name: "pgp without end", signature: "-----BEGIN PGP SIGNATURE-----\n\niHUEABYIAB0WIQRToabw", sigType: SignatureTypeGPG},
+		{name: "pgp invalid base64", signature: "-----BEGIN PGP SIGNATURE-----\n\n!!!!\n-----END PGP SIGNATURE-----", sigType: SignatureTypeGPG},
+		{name: "pgp empty body", signature: "-----BEGIN PGP SIGNATURE-----\n\n-----END PGP SIGNATURE-----", sigType: SignatureTypeGPG},
+		{name: "ssh empty body", signature: "-----BEGIN SSH SIGNATURE-----\n-----END SSH SIGNATURE-----", sigType: SignatureTypeSSH},
+		{name: "x509", signature: "-----BEGIN SIGNED MESSAGE-----\nMIAGCSqGSIb3DQEHAqCAMIACAQEx\n-----END SIGNED MESSAGE-----", sigType: SignatureTypeX509},
+	}
+	for _, tt := range tests {
+		sigType, keyID := ParseSignatureKeyID(tt.signature)
+		if sigType != tt.sigType || keyID != tt.keyID {
+			t.Errorf("%s: ParseSignatureKeyID = (%q, %q), expected (%q, %q)", tt.name, sigType, keyID, tt.sigType, tt.keyID)
+		}
+	}
+}
+
+// testPGPSubpacket builds a signature subpacket (with a one-byte length).
+func testPGPSubpacket(typ byte, data []byte) []byte {
+	return append([]byte{byte(1 + len(data)), typ}, data...)
+}
+
+// testPGPSignatureBody builds a v4 signature packet body with the subpackets.
+func testPGPSignatureBody(hashed []byte, unhashed []byte) []byte {
+	body := []byte{4, 0x00, 22, 8}
+	body = append(body, byte(len(hashed)>>8), byte(len(hashed)))
+	body = append(body, hashed...)
+	body = append(body, byte(len(unhashed)>>8), byte(len(unhashed)))
+	body = append(body, unhashed...)
+	// hash prefix and a fake signature.
+	return append(body, 0xAB, 0xCD, 0x01, 0x00, 0x01)
+}
+
+func testPGPNewPacket(body []byte) []byte {
+	return append([]byte{0xC0 | 2, 0xFF, 0, 0, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+func testPGPOldPacket(body []byte) []byte {
+	return append([]byte{0x80 | 2<<2 | 1, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+func TestPGPIssuerKeyID(t *testing.T) {
+	issuer := []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}
+	fingerprint := append([]byte{4}, bytes.Repeat([]byte{0xEE}, 12)...)
+	fingerprint = append(fingerprint, issuer...)
+	created := []byte{0, 0, 0, 1}
+
+	v3 := []byte{3, 5, 0x00, 0, 0, 0, 1}
+	v3 = append(v3, issuer...)
+	v3 = append(v3, 22, 8, 0xAB, 0xCD)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		keyID string
+	}{
+		{
+			name:  "issuer in unhashed, new format",
+			data:  testPGPNewPacket(testPGPSignatureBody(testPGPSubpacket(2, created), testPGPSubpacket(16, issuer))),
+			keyID: "1122334455667788",
+		},
+		{
+			name:  "issuer in unhashed, old format",
+			data:  testPGPOldPacket(testPGPSignatureBody(testPGPSubpacket(2, created), testPGPSubpacket(16, issuer))),
+			keyID: "1122334455667788",
+		},
+		{
+			name:  "issuer fingerprint in hashed",
+			data:  testPGPNewPacket(testPGPSignatureBody(testPGPSubpacket(33, fingerprint), nil)),
+			keyID: "1122334455667788",
+		},
+		{
+			name:  "critical issuer",
+			data:  testPGPNewPacket(testPGPSignatureBody(testPGPSubpacket(0x80|16, issuer), nil)),
+			keyID: "1122334455667788",
+		},
+		{
+			name:  "v3",
+			data:  testPGPOldPacket(v3),
+			keyID: "1122334455667788",
+		},
+		{
+			name: "no issuer",
+			data: testPGPNewPacket(testPGPSignatureBody(testPGPSubpacket(2, created), nil)),
+		},
+		{
+			name: "issuer with wrong length",
+			data: testPGPNewPacket(testPGPSignatureBody(nil, testPGPSubpacket(16, issuer[:7]))),
+		},
+		{
+			name: "v5 fingerprint",
+			data: testPGPNewPacket(testPGPSignatureBody(testPGPSubpacket(33, append([]byte{5}, bytes.Repeat([]byte{0xEE}, 32)...)), nil)),
+		},
+		{
+			name: "zero-length subpacket",
+			data: testPGPNewPacket(testPGPSignatureBody(nil, append([]byte{0}, testPGPSubpacket(16, issuer)...))),
+		},
+		{
+			name: "subpacket longer than the area",
+			data: testPGPNewPacket(testPGPSignatureBody([]byte{50, 16, 1, 2}, nil)),
+		},
+		{
+			name: "not a signature packet",
+			data: append([]byte{0xC0 | 6, 3}, 4, 0, 0),
+		},
+		{
+			name: "not a packet",
+			data: []byte{0x00, 0x01, 0x02},
+		},
+		{
+			name: "huge length",
+			data: []byte{0xC0 | 2, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 4},
+		},
+		{
+			name: "partial length",
+			data: []byte{0xC0 | 2, 0xE1, 4, 0, 22, 8},
+		},
+		{
+			name: "empty",
+			data: nil,
+		},
+	}
+	for _, tt := range tests {
+		if keyID := pgpIssuerKeyID(tt.data); keyID != tt.keyID {
+			t.Errorf("%s: pgpIssuerKeyID = %q, expected %q", tt.name, keyID, tt.keyID)
+		}
+	}
+}
+
+func TestPGPIssuerKeyIDTruncated(t *testing.T) {
+	data, err := decodeArmor(testPGPSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyID := pgpIssuerKeyID(data); keyID != "B7A733318304CB74" {
+		t.Fatalf("pgpIssuerKeyID = %q", keyID)
+	}
+	// every truncation is shorter than the packet length: no key ID, and no panic.
+	for i := 0; i < len(data); i++ {
+		if keyID := pgpIssuerKeyID(data[:i]); keyID != "" {
+			t.Errorf("pgpIssuerKeyID(data[:%d]) = %q, expected none", i, keyID)
+		}
+	}
+}
+
+func TestSSHSignatureFingerprint(t *testing.T) {
+	key := []byte("\x00\x00\x00\x0bssh-ed25519\x00\x00\x00\x20" + string(bytes.Repeat([]byte{0x42}, 32)))
+	sum := sha256.Sum256(key)
+	fingerprint := "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+
+	build := func(keyLen uint32, key []byte) []byte {
+		data := []byte("SSHSIG\x00\x00\x00\x01")
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, keyLen)
+		data = append(data, length...)
+		return append(data, key...)
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		fingerprint string
+	}{
+		{name: "valid", data: build(uint32(len(key)), key), fingerprint: fingerprint},
+		{name: "key length too long", data: build(uint32(len(key)+1), key)},
+		{name: "huge key length", data: build(0xFFFFFFFF, key)},
+		{name: "no key length", data: []byte("SSHSIG\x00\x00\x00\x01")},
+		{name: "bad magic", data: append([]byte("SSHSIX"), build(uint32(len(key)), key)[6:]...)},
+		{name: "empty", data: nil},
+	}
+	for _, tt := range tests {
+		if got := sshSignatureFingerprint(tt.data); got != tt.fingerprint {
+			t.Errorf("%s: sshSignatureFingerprint = %q, expected %q", tt.name, got, tt.fingerprint)
+		}
+	}
+
+	data, err := decodeArmor(testSSHSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the public key is at the start: truncations after it still have the fingerprint.
+	keyEnd := 10 + 4 + int(binary.BigEndian.Uint32(data[10:14]))
+	for i := 0; i < len(data); i++ {
+		got := sshSignatureFingerprint(data[:i])
+		if i < keyEnd && got != "" {
+			t.Errorf("sshSignatureFingerprint(data[:%d]) = %q, expected none", i, got)
+		}
+		if i >= keyEnd && got == "" {
+			t.Errorf("sshSignatureFingerprint(data[:%d]): expected a fingerprint", i)
+		}
+	}
+}
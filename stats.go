@@ -0,0 +1,198 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// ErrStatsNotReady is returned when GitHub is still computing
+// the statistics of a repo when the timeout expires.
+var ErrStatsNotReady = errors.New("statistics not ready")
+
+// DefaultStatsTimeout is the default time to wait for GitHub
+// to compute the statistics of a repo.
+const DefaultStatsTimeout = 2 * time.Minute
+
+const maxStatsPollInterval = 30 * time.Second
+
+// getStats executes the request of a statistics endpoint;
+// while GitHub is computing the statistics (202 Accepted),
+// the request is repeated with backoff until the timeout (<= 0 means DefaultStatsTimeout).
+func (c *Client) getStats(timeout time.Duration, do func(ctx context.Context) (*github.Response, error)) error {
+	if timeout <= 0 {
+		timeout = DefaultStatsTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	interval := time.Second
+
+	for {
+		var accepted bool
+		var resp *github.Response
+		errs := RetryExponentialBackoff(5, time.Second, func() error {
+			var err error
+			accepted = false
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+
+			resp, err = do(ctx)
+			if _, ok := err.(*github.AcceptedError); ok {
+				onResponse(resp)
+				accepted = true
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("error while executing request: %w", err)
+			}
+			onResponse(resp)
+			if handleRateLimitError(err, resp) {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+				// TODO: catch rate limit error, and wait
+				return fmt.Errorf(
+					"status code is: %v (%s)",
+					resp.StatusCode,
+					resp.Status,
+				)
+			}
+			// nil on 200 and 404
+			return nil
+		})
+		if errs != nil && len(errs) > 0 {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return ErrNotFound
+			}
+			return errors.New(FormatErrorArray("", errs))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+		if !accepted {
+			return nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return ErrStatsNotReady
+		}
+		time.Sleep(interval)
+		interval *= 2
+		if interval > maxStatsPollInterval {
+			interval = maxStatsPollInterval
+		}
+	}
+}
+
+// ListContributorsStats returns, for each contributor, the total number of commits
+// and the weekly additions, deletions and commits.
+// If GitHub is still computing the statistics, it waits up to the timeout
+// (<= 0 means DefaultStatsTimeout), then returns ErrStatsNotReady.
+func (c *Client) ListContributorsStats(owner string, repo string, timeout time.Duration) ([]*github.ContributorStats, error) {
+	var stats []*github.ContributorStats
+	err := c.getStats(timeout, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		stats, resp, err = c.client.Repositories.ListContributorsStats(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListCommitActivity returns the number of commits per day of the last year, grouped by week.
+// See ListContributorsStats for the timeout.
+func (c *Client) ListCommitActivity(owner string, repo string, timeout time.Duration) ([]*github.WeeklyCommitActivity, error) {
+	var stats []*github.WeeklyCommitActivity
+	err := c.getStats(timeout, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		stats, resp, err = c.client.Repositories.ListCommitActivity(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListCodeFrequency returns the weekly additions and deletions of the repo.
+// See ListContributorsStats for the timeout.
+func (c *Client) ListCodeFrequency(owner string, repo string, timeout time.Duration) ([]*github.WeeklyStats, error) {
+	var stats []*github.WeeklyStats
+	err := c.getStats(timeout, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		stats, resp, err = c.client.Repositories.ListCodeFrequency(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListParticipation returns the weekly number of commits of the last year,
+// of everyone and of the owner.
+// See ListContributorsStats for the timeout.
+func (c *Client) ListParticipation(owner string, repo string, timeout time.Duration) (*github.RepositoryParticipation, error) {
+	var stats *github.RepositoryParticipation
+	err := c.getStats(timeout, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		stats, resp, err = c.client.Repositories.ListParticipation(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListPunchCard returns the number of commits per hour of each day of the week.
+// See ListContributorsStats for the timeout.
+func (c *Client) ListPunchCard(owner string, repo string, timeout time.Duration) ([]*github.PunchCard, error) {
+	var stats []*github.PunchCard
+	err := c.getStats(timeout, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		stats, resp, err = c.client.Repositories.ListPunchCard(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ContributorChurn sums the weekly stats of each contributor (by login)
+// for the weeks that start in the window; zero since or until means no bound.
+func ContributorChurn(stats []*github.ContributorStats, since time.Time, until time.Time) map[string]*Churn {
+	churn := make(map[string]*Churn)
+	for _, contributor := range stats {
+		login := contributor.GetAuthor().GetLogin()
+		total := &Churn{}
+		for _, week := range contributor.Weeks {
+			start := week.GetWeek().Time
+			if !since.IsZero() && start.Before(since) {
+				continue
+			}
+			if !until.IsZero() && start.After(until) {
+				continue
+			}
+			total.Commits += week.GetCommits()
+			total.Additions += week.GetAdditions()
+			total.Deletions += week.GetDeletions()
+		}
+		churn[login] = total
+	}
+	return churn
+}
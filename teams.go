@@ -0,0 +1,343 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+const (
+	TeamRoleAll        = "all"
+	TeamRoleMember     = "member"
+	TeamRoleMaintainer = "maintainer"
+)
+
+// ListTeams lists the teams of the org (visible to the authenticated user).
+func (c *Client) ListTeams(org string) ([]*github.Team, error) {
+	var all []*github.Team
+	var page []*github.Team
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.Teams.ListTeams(ctx, org, opt)
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// TeamRepo is a repo a team has access to.
+type TeamRepo struct {
+	github.Repository
+	RoleName *string `json:"role_name,omitempty"`
+}
+
+// Permission returns the permission level of the team on the repo.
+func (r *TeamRepo) Permission() Permission {
+	var roleName string
+	if r.RoleName != nil {
+		roleName = *r.RoleName
+	}
+	return permissionOf(roleName, r.Permissions)
+}
+
+// ListTeamRepos lists the repos the team (by slug) has access to.
+func (c *Client) ListTeamRepos(org string, team string) ([]*TeamRepo, error) {
+	var all []*TeamRepo
+	var page []*TeamRepo
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("orgs/%v/teams/%v/repos", org, team)
+			u, err := addOptions(u, opt)
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+type listTeamMembersOptions struct {
+	Role string `url:"role,omitempty"`
+	github.ListOptions
+}
+
+// ListTeamMembers lists the members of the team (by slug), including the members
+// of its child teams; role is TeamRoleAll (or empty), TeamRoleMember or TeamRoleMaintainer.
+func (c *Client) ListTeamMembers(org string, team string, role string) ([]*github.User, error) {
+	var all []*github.User
+	var page []*github.User
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			u := fmt.Sprintf("orgs/%v/teams/%v/members", org, team)
+			u, err := addOptions(u, &listTeamMembersOptions{
+				Role:        role,
+				ListOptions: *opt,
+			})
+			if err != nil {
+				return nil, err
+			}
+			req, err := c.client.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page = nil
+			return c.client.Do(ctx, req, &page)
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// TeamNode is a team with its child teams.
+type TeamNode struct {
+	Team     *github.Team
+	Children []*TeamNode
+}
+
+// Walk calls the function for the node and all its descendants, depth-first;
+// depth is 0 for the node.
+func (n *TeamNode) Walk(fn func(node *TeamNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *TeamNode) walk(fn func(node *TeamNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// BuildTeamTree arranges the teams in trees by parent;
+// the roots are the teams without a parent (or whose parent is not in teams).
+// The roots and the children are sorted by slug.
+func BuildTeamTree(teams []*github.Team) []*TeamNode {
+	nodes := make(map[int64]*TeamNode)
+	for _, team := range teams {
+		nodes[team.GetID()] = &TeamNode{Team: team}
+	}
+
+	var roots []*TeamNode
+	for _, team := range teams {
+		node := nodes[team.GetID()]
+		parent, ok := nodes[team.GetParent().GetID()]
+		if team.Parent == nil || !ok {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	var sortNodes func(nodes []*TeamNode)
+	sortNodes = func(nodes []*TeamNode) {
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].Team.GetSlug() < nodes[j].Team.GetSlug()
+		})
+		for _, node := range nodes {
+			sortNodes(node.Children)
+		}
+	}
+	sortNodes(roots)
+	return roots
+}
+
+// GetTeamTree returns the teams of the org arranged in trees by parent.
+func (c *Client) GetTeamTree(org string) ([]*TeamNode, error) {
+	teams, err := c.ListTeams(org)
+	if err != nil {
+		return nil, err
+	}
+	return BuildTeamTree(teams), nil
+}
+
+// TeamMember is a member of a team, with the role in the team.
+type TeamMember struct {
+	User *github.User
+	// Role is TeamRoleMaintainer or TeamRoleMember.
+	Role string
+}
+
+// ListTeamMembersWithRoles lists the members of the team (by slug) with their roles.
+func (c *Client) ListTeamMembersWithRoles(org string, team string) ([]*TeamMember, error) {
+	maintainers, err := c.ListTeamMembers(org, team, TeamRoleMaintainer)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing maintainers: %w", err)
+	}
+	users, err := c.ListTeamMembers(org, team, TeamRoleAll)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing members: %w", err)
+	}
+
+	isMaintainer := make(map[int64]bool)
+	for _, user := range maintainers {
+		isMaintainer[user.GetID()] = true
+	}
+	members := make([]*TeamMember, 0, len(users))
+	for _, user := range users {
+		member := &TeamMember{
+			User: user,
+			Role: TeamRoleMember,
+		}
+		if isMaintainer[user.GetID()] {
+			member.Role = TeamRoleMaintainer
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// GetTeamMembership returns the membership of the user in the team (by slug);
+// ErrNotFound if the user is not a member.
+func (c *Client) GetTeamMembership(org string, team string, user string) (*github.Membership, error) {
+	client := c.client
+
+	u := fmt.Sprintf("orgs/%v/teams/%v/memberships/%v", org, team, user)
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var membership *github.Membership
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		membership = new(github.Membership)
+		resp, err = client.Do(ctx, req, membership)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// not a member: not an error.
+			onResponse(resp)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			// TODO: catch rate limit error, and wait
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	return membership, nil
+}
+
+// UserTeam is a team a user belongs to.
+type UserTeam struct {
+	Team *github.Team
+	// Role is TeamRoleMaintainer or TeamRoleMember; empty if Inherited.
+	Role string
+	// State is "active" or "pending"; empty if Inherited.
+	State string
+	// Inherited is set when the user is not reported as a member of the team,
+	// but is a member of one of its descendants.
+	Inherited bool
+}
+
+// GetEffectiveTeams returns all the teams the user belongs to,
+// including the ancestors of the teams the user is a member of
+// (members of a child team inherit the access of the parent teams).
+// NOTE: each team of the org costs one extra request.
+func (c *Client) GetEffectiveTeams(org string, user string) ([]*UserTeam, error) {
+	teams, err := c.ListTeams(org)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListTeams: %w", err)
+	}
+	byID := make(map[int64]*github.Team)
+	for _, team := range teams {
+		byID[team.GetID()] = team
+	}
+
+	memberships := make(map[int64]*github.Membership)
+	for _, team := range teams {
+		membership, err := c.GetTeamMembership(org, team.GetSlug(), user)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error while getting membership of team %s: %w", team.GetSlug(), err)
+		}
+		memberships[team.GetID()] = membership
+	}
+
+	ancestors := make(map[int64]bool)
+	for id := range memberships {
+		for parent := byID[id].GetParent(); parent != nil; parent = byID[parent.GetID()].GetParent() {
+			if ancestors[parent.GetID()] {
+				break
+			}
+			ancestors[parent.GetID()] = true
+		}
+	}
+
+	var effective []*UserTeam
+	for _, team := range teams {
+		if membership, ok := memberships[team.GetID()]; ok {
+			effective = append(effective, &UserTeam{
+				Team:  team,
+				Role:  membership.GetRole(),
+				State: membership.GetState(),
+			})
+			continue
+		}
+		if ancestors[team.GetID()] {
+			effective = append(effective, &UserTeam{
+				Team:      team,
+				Inherited: true,
+			})
+		}
+	}
+	return effective, nil
+}
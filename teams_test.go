@@ -0,0 +1,52 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestGetEffectiveTeams(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/orgs/org/teams":
+			fmt.Fprint(w, `[
+				{"id": 1, "slug": "parent"},
+				{"id": 2, "slug": "child", "parent": {"id": 1, "slug": "parent"}},
+				{"id": 3, "slug": "other"}
+			]`)
+		case "/orgs/org/teams/child/memberships/alice":
+			fmt.Fprint(w, `{"role": "maintainer", "state": "active"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	teams, err := client.GetEffectiveTeams("org", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 2 {
+		t.Fatalf("got %d teams, expected 2", len(teams))
+	}
+	if team := teams[0]; team.Team.GetSlug() != "parent" || !team.Inherited {
+		t.Errorf("got %s (inherited: %v), expected the inherited parent", team.Team.GetSlug(), team.Inherited)
+	}
+	if team := teams[1]; team.Team.GetSlug() != "child" || team.Inherited || team.Role != TeamRoleMaintainer {
+		t.Errorf("got %s (inherited: %v, role: %q), expected child as maintainer", team.Team.GetSlug(), team.Inherited, team.Role)
+	}
+
+	// not being a member is not an error to retry.
+	for _, slug := range []string{"parent", "other"} {
+		path := "/orgs/org/teams/" + slug + "/memberships/alice"
+		if n := requests[path]; n != 1 {
+			t.Errorf("%s requested %d times, expected once", path, n)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// ListPublicMembers lists the members of the org that show their membership publicly.
+func (c *Client) ListPublicMembers(org string) ([]*github.User, error) {
+	var all []*github.User
+	var page []*github.User
+	err := c.listAllPages(
+		func(ctx context.Context, opt *github.ListOptions) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			page, resp, err = c.client.Organizations.ListMembers(ctx, org, &github.ListMembersOptions{
+				PublicOnly:  true,
+				ListOptions: *opt,
+			})
+			return resp, err
+		},
+		func() {
+			all = append(all, page...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// checkMembershipURL requests a membership endpoint, which answers
+// 204 if the user is a member, and 404 if not.
+// It returns the response, whose Request is the last one in case of redirects.
+func (c *Client) checkMembershipURL(u string) (bool, *github.Response, error) {
+	client := c.client
+
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		resp, err = client.Do(ctx, req, nil)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// not a member: not an error.
+			onResponse(resp)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+		if handleRateLimitError(err, resp) {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusNoContent {
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		return nil
+	})
+	if errs != nil && len(errs) > 0 {
+		return false, nil, errors.New(FormatErrorArray("", errs))
+	}
+	return resp.StatusCode == http.StatusNoContent, resp, nil
+}
+
+// IsPublicMember tells whether the user shows publicly the membership of the org.
+func (c *Client) IsPublicMember(org string, user string) (bool, error) {
+	isMember, _, err := c.checkMembershipURL(fmt.Sprintf("orgs/%v/public_members/%v", org, user))
+	return isMember, err
+}
+
+// MembershipCheck is the result of IsMember.
+type MembershipCheck struct {
+	Member bool
+	// Conclusive is false when the authenticated user is not a member of the org:
+	// GitHub then redirects (302) the check to the public membership,
+	// so a member who conceals the membership is reported as not a member.
+	Conclusive bool
+}
+
+// IsMember tells whether the user is a member of the org.
+func (c *Client) IsMember(org string, user string) (*MembershipCheck, error) {
+	isMember, resp, err := c.checkMembershipURL(fmt.Sprintf("orgs/%v/members/%v", org, user))
+	if err != nil {
+		return nil, err
+	}
+	// the redirect is followed by the http.Client:
+	redirected := resp.Request != nil && strings.Contains(resp.Request.URL.Path, "/public_members/")
+	return &MembershipCheck{
+		Member:     isMember,
+		Conclusive: !redirected,
+	}, nil
+}
+
+// MembershipVisibilityReport tells apart the public and the concealed members of an org.
+type MembershipVisibilityReport struct {
+	Org string `json:"org"`
+	// ConcealedVisible tells whether the authenticated user is a member of the org,
+	// i.e. whether the concealed members could be listed.
+	ConcealedVisible bool `json:"concealed_visible"`
+
+	Public    []string `json:"public"`
+	Concealed []string `json:"concealed"`
+}
+
+// BuildMembershipVisibilityReport lists the public and the concealed members of the org;
+// the concealed members are listed only if the authenticated user is a member of the org.
+func (c *Client) BuildMembershipVisibilityReport(org string) (*MembershipVisibilityReport, error) {
+	me, err := c.GetUser("")
+	if err != nil {
+		return nil, fmt.Errorf("error while getting the authenticated user: %w", err)
+	}
+	check, err := c.IsMember(org, me.GetLogin())
+	if err != nil {
+		return nil, fmt.Errorf("error while checking the membership of %s: %w", me.GetLogin(), err)
+	}
+
+	public, err := c.ListPublicMembers(org)
+	if err != nil {
+		return nil, fmt.Errorf("error while ListPublicMembers: %w", err)
+	}
+	report := &MembershipVisibilityReport{
+		Org:              org,
+		ConcealedVisible: check.Member && check.Conclusive,
+	}
+	isPublic := make(map[string]bool)
+	for _, user := range public {
+		isPublic[user.GetLogin()] = true
+		report.Public = append(report.Public, user.GetLogin())
+	}
+
+	if report.ConcealedVisible {
+		members, err := c.ListOrgMembers(org, &ListOrgMembersOpts{})
+		if err != nil {
+			return nil, fmt.Errorf("error while ListOrgMembers: %w", err)
+		}
+		for _, user := range members {
+			if !isPublic[user.GetLogin()] {
+				report.Concealed = append(report.Concealed, user.GetLogin())
+			}
+		}
+	}
+
+	sort.Strings(report.Public)
+	sort.Strings(report.Concealed)
+	return report, nil
+}
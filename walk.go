@@ -0,0 +1,300 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/gagliardetto/utilz"
+	"github.com/google/go-github/github"
+)
+
+// WalkOrder specifies the order in which a parallel walk
+// delivers the repository contents to the walker.
+type WalkOrder int
+
+const (
+	// WalkOrderDeterministic delivers the contents in the same order
+	// as the sequential walk (the contents of a dir first, then the dir itself).
+	WalkOrderDeterministic WalkOrder = iota
+	// WalkOrderUnordered delivers the contents as soon as the listing
+	// of their parent dir is available.
+	WalkOrderUnordered
+)
+
+// WithConcurrency sets the number of directories that WalkFiles
+// will list at the same time. Values <= 1 mean a sequential walk.
+func (r *RepoExplorationRequest) WithConcurrency(workers int) *RepoExplorationRequest {
+	r.concurrency = workers
+	return r
+}
+
+// WithWalkOrder sets the order in which a parallel walk
+// delivers the contents to the walker.
+func (r *RepoExplorationRequest) WithWalkOrder(order WalkOrder) *RepoExplorationRequest {
+	r.order = order
+	return r
+}
+
+type dirListing struct {
+	path    string
+	content []*github.RepositoryContent
+	err     error
+
+	// done is closed when the listing is complete,
+	// and all its subdirectories have been scheduled.
+	done chan struct{}
+}
+
+type parallelWalk struct {
+	r *RepoExplorationRequest
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	listings map[string]*dirListing
+	// queue are the listings waiting for a worker.
+	queue []*dirListing
+	// active is the number of listings queued or in progress.
+	active int
+
+	// results is used only in unordered mode.
+	results chan *dirListing
+}
+
+// walkFilesParallel walks the repository listing up to r.concurrency
+// directories at the same time; the walker is always called
+// from the calling goroutine, so it does not need to be thread-safe.
+// Errors while listing a directory do not stop the walk: the directory
+// is skipped, and all such errors are returned together at the end.
+func (r *RepoExplorationRequest) walkFilesParallel(walker func(v *github.RepositoryContent) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &parallelWalk{
+		r:        r,
+		ctx:      ctx,
+		cancel:   cancel,
+		listings: make(map[string]*dirListing),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	if r.order == WalkOrderUnordered {
+		w.results = make(chan *dirListing)
+	}
+
+	root := w.schedule(r.params.path)
+	for i := 0; i < r.concurrency; i++ {
+		w.wg.Add(1)
+		go w.work()
+	}
+
+	var err error
+	var errs []error
+	if r.order == WalkOrderUnordered {
+		errs, err = w.deliverUnordered(root, walker)
+	} else {
+		<-root.done
+		if root.err != nil {
+			err = root.err
+		} else {
+			errs, err = w.deliverOrdered(root, walker)
+		}
+	}
+	// stop any pending listing, and wait for the workers to exit:
+	cancel()
+	w.mu.Lock()
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	w.wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errors.New(FormatErrorArray("", errs))
+	}
+	return nil
+}
+
+// schedule queues the listing of the provided path.
+func (w *parallelWalk) schedule(path string) *dirListing {
+	listing := &dirListing{
+		path: path,
+		done: make(chan struct{}),
+	}
+	w.mu.Lock()
+	w.listings[path] = listing
+	w.queue = append(w.queue, listing)
+	w.active++
+	w.cond.Signal()
+	w.mu.Unlock()
+	return listing
+}
+
+// work lists the queued directories until there are no more,
+// or the walk is stopped.
+func (w *parallelWalk) work() {
+	defer w.wg.Done()
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 && w.active > 0 && w.ctx.Err() == nil {
+			w.cond.Wait()
+		}
+		if len(w.queue) == 0 || w.ctx.Err() != nil {
+			w.mu.Unlock()
+			return
+		}
+		listing := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+
+		listing.content, listing.err = w.r.listDir(w.ctx, listing.path)
+		if listing.err == nil {
+			for _, v := range listing.content {
+				if IsDir(v) {
+					w.schedule(v.GetPath())
+				}
+			}
+		}
+		close(listing.done)
+
+		if w.results != nil {
+			select {
+			case w.results <- listing:
+			case <-w.ctx.Done():
+			}
+		}
+
+		w.mu.Lock()
+		w.active--
+		if w.active == 0 {
+			// all done: wake up the idle workers, so that they exit.
+			w.cond.Broadcast()
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *parallelWalk) take(path string) *dirListing {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	listing := w.listings[path]
+	delete(w.listings, path)
+	return listing
+}
+
+// deliverOrdered calls the walker in the same order as walkFiles.
+func (w *parallelWalk) deliverOrdered(listing *dirListing, walker func(v *github.RepositoryContent) error) ([]error, error) {
+	var errs []error
+	for _, v := range listing.content {
+		if IsDir(v) {
+			sub := w.take(v.GetPath())
+			<-sub.done
+			if sub.err != nil {
+				errs = append(errs, fmt.Errorf("error while listing %q: %w", sub.path, sub.err))
+			} else {
+				subErrs, err := w.deliverOrdered(sub, walker)
+				errs = append(errs, subErrs...)
+				if err != nil {
+					return errs, err
+				}
+			}
+		}
+
+		err := walker(v)
+		if err != nil {
+			return errs, err
+		}
+	}
+	return errs, nil
+}
+
+// deliverUnordered calls the walker as soon as the listings are completed.
+func (w *parallelWalk) deliverUnordered(root *dirListing, walker func(v *github.RepositoryContent) error) ([]error, error) {
+	var errs []error
+	// pending is the number of scheduled listings not yet received.
+	pending := 1
+	for pending > 0 {
+		listing := <-w.results
+		pending--
+		w.take(listing.path)
+
+		if listing.err != nil {
+			if listing == root {
+				return errs, listing.err
+			}
+			errs = append(errs, fmt.Errorf("error while listing %q: %w", listing.path, listing.err))
+			continue
+		}
+		for _, v := range listing.content {
+			if IsDir(v) {
+				pending++
+			}
+			err := walker(v)
+			if err != nil {
+				return errs, err
+			}
+		}
+	}
+	return errs, nil
+}
+
+// listDir lists the contents of a directory, waiting for the rate limit
+// reset together with the other workers of the same Client.
+func (r *RepoExplorationRequest) listDir(parent context.Context, path string) ([]*github.RepositoryContent, error) {
+	var directoryContent []*github.RepositoryContent
+	var resp *github.Response
+	errs := RetryExponentialBackoff(5, time.Second, func() error {
+		var err error
+
+		if r.client.rateGate.waitContext(parent) != nil {
+			// the walk was stopped.
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(parent, time.Second*10)
+		defer cancel()
+
+		_, directoryContent, resp, err = r.client.client.Repositories.GetContents(ctx, r.params.owner, r.params.repo, path, r.contentGetOptions())
+		r.client.rateGate.update(err, resp)
+		if parent.Err() != nil {
+			// the walk was stopped.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while executing request: %w", err)
+		}
+		onResponse(resp)
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusNoContent {
+			return fmt.Errorf(
+				"status code is: %v (%s)",
+				resp.StatusCode,
+				resp.Status,
+			)
+		}
+		// nil on 200 and 404
+		return nil
+	})
+	if parent.Err() != nil {
+		return nil, parent.Err()
+	}
+	if errs != nil && len(errs) > 0 {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(FormatErrorArray("", errs))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	return directoryContent, nil
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// testTree maps the path of each directory to the names of its entries;
+// names ending with "/" are directories.
+var testTree = map[string][]string{
+	"":      {"a/", "b/", "README.md"},
+	"a":     {"a/c/", "a/x.go"},
+	"a/c":   {"a/c/y.go", "a/c/z.go"},
+	"b":     {"b/w.go", "b/d/"},
+	"b/d":   {"b/d/e/"},
+	"b/d/e": {"b/d/e/v.go"},
+}
+
+func serveTestTree(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/contents")
+	path = strings.Trim(path, "/")
+	entries, ok := testTree[path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var content []*github.RepositoryContent
+	for _, name := range entries {
+		typ := "file"
+		if strings.HasSuffix(name, "/") {
+			typ = "dir"
+			name = strings.TrimSuffix(name, "/")
+		}
+		content = append(content, &github.RepositoryContent{
+			Type: github.String(typ),
+			Path: github.String(name),
+		})
+	}
+	json.NewEncoder(w).Encode(content)
+}
+
+func walkTestTree(t *testing.T, configure func(r *RepoExplorationRequest)) ([]string, error) {
+	t.Helper()
+	client := newTestClient(t, http.HandlerFunc(serveTestTree))
+	req := client.NewRepoExplorationRequest().WithOwner("owner").WithRepo("repo")
+	configure(req)
+
+	var paths []string
+	err := req.WalkFiles(func(v *github.RepositoryContent) error {
+		paths = append(paths, v.GetPath())
+		return nil
+	})
+	return paths, err
+}
+
+func TestWalkFilesParallel(t *testing.T) {
+	sequential, err := walkTestTree(t, func(r *RepoExplorationRequest) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sequential) != 11 {
+		t.Fatalf("sequential walk returned %d entries, want 11: %v", len(sequential), sequential)
+	}
+
+	for _, workers := range []int{2, 3, 16} {
+		ordered, err := walkTestTree(t, func(r *RepoExplorationRequest) {
+			r.WithConcurrency(workers)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(ordered, ",") != strings.Join(sequential, ",") {
+			t.Errorf("workers=%d: ordered walk = %v, want %v", workers, ordered, sequential)
+		}
+
+		unordered, err := walkTestTree(t, func(r *RepoExplorationRequest) {
+			r.WithConcurrency(workers).WithWalkOrder(WalkOrderUnordered)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(unordered)
+		want := append([]string(nil), sequential...)
+		sort.Strings(want)
+		if strings.Join(unordered, ",") != strings.Join(want, ",") {
+			t.Errorf("workers=%d: unordered walk = %v, want %v", workers, unordered, want)
+		}
+	}
+}
+
+func TestWalkFilesParallelStop(t *testing.T) {
+	errStop := errors.New("stop")
+	for _, order := range []WalkOrder{WalkOrderDeterministic, WalkOrderUnordered} {
+		client := newTestClient(t, http.HandlerFunc(serveTestTree))
+		req := client.NewRepoExplorationRequest().
+			WithOwner("owner").
+			WithRepo("repo").
+			WithConcurrency(2).
+			WithWalkOrder(order)
+
+		calls := 0
+		err := req.WalkFiles(func(v *github.RepositoryContent) error {
+			calls++
+			return errStop
+		})
+		if err != errStop {
+			t.Errorf("order=%v: err = %v, want %v", order, err, errStop)
+		}
+		if calls != 1 {
+			t.Errorf("order=%v: walker called %d times, want 1", order, calls)
+		}
+	}
+}